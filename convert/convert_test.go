@@ -0,0 +1,263 @@
+package convert_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/cheekybits/is"
+	"github.com/matryer/silk/convert"
+	"github.com/matryer/silk/document"
+	"github.com/matryer/silk/parse"
+	"github.com/matryer/silk/runner"
+	"github.com/matryer/silk/testutil"
+)
+
+// roundTrip writes the file and parses it again.
+func roundTrip(t *testing.T, file *document.File) []*parse.Group {
+	var buf bytes.Buffer
+	if err := document.Write(&buf, file.Groups...); err != nil {
+		t.Fatalf("write: %s", err)
+	}
+	groups, err := parse.Parse(file.Name, &buf)
+	if err != nil {
+		t.Fatalf("parse: %s\n%s", err, buf.String())
+	}
+	return groups
+}
+
+func TestHTTP(t *testing.T) {
+	is := is.New(t)
+	f, err := os.Open("../testfiles/success/import/users.http")
+	is.NoErr(err)
+	defer f.Close()
+	file, err := convert.HTTP("users.http", f)
+	is.NoErr(err)
+	is.Equal(file.Name, "users.silk.md")
+	groups := roundTrip(t, file)
+	is.Equal(len(groups), 1)
+	group := groups[0]
+	is.Equal(string(group.Title), "users")
+	is.Equal(len(group.Details), 2)
+	is.Equal(group.Details[0].Detail().Key, "{host}")
+	is.Equal(group.Details[0].Detail().Value.Data, "http://localhost:8080")
+	is.Equal(len(group.Requests), 4)
+
+	list := group.Requests[0]
+	is.Equal(string(list.Method), "GET")
+	is.Equal(string(list.Path), "/users")
+	is.Equal(list.Params[0].Detail().Key, "limit")
+	is.Equal(list.Params[0].Detail().Value.Data, "{limit}")
+	is.Equal(list.Details[0].Detail().Key, "Accept")
+	is.Equal(list.ExpectedDetails[0].Detail().Key, "Status")
+	is.Equal(list.ExpectedDetails[0].Detail().Value.Data, 200.0)
+
+	create := group.Requests[1]
+	is.Equal(string(create.Method), "POST")
+	is.Equal(create.BodyType, "json")
+	is.Equal(create.ExpectedDetails[0].Detail().Value.Data, 201.0)
+
+	get := group.Requests[2]
+	is.Equal(string(get.Path), "/users/2")
+	is.Equal(len(get.Params), 2)
+	is.Equal(get.Params[1].Detail().Key, "fields")
+
+	upload := group.Requests[3]
+	is.NotNil(upload.BodyFile)
+	is.Equal(upload.BodyFile.Path, "user.json")
+}
+
+func TestHTTPRun(t *testing.T) {
+	is := is.New(t)
+	f, err := os.Open("../testfiles/success/import/users.http")
+	is.NoErr(err)
+	defer f.Close()
+	file, err := convert.HTTP("users.http", f)
+	is.NoErr(err)
+	groups := roundTrip(t, file)
+	// skip the upload, which has no server
+	groups[0].Requests = groups[0].Requests[:3]
+	s := httptest.NewServer(testutil.UsersHandler())
+	defer s.Close()
+	r := runner.New(t, s.URL)
+	r.RunGroup(groups...)
+}
+
+func TestPostman(t *testing.T) {
+	is := is.New(t)
+	f, err := os.Open("../testfiles/success/import/users.postman_collection.json")
+	is.NoErr(err)
+	defer f.Close()
+	file, err := convert.Postman("users.postman_collection.json", f)
+	is.NoErr(err)
+	is.Equal(file.Name, "users.silk.md")
+	groups := roundTrip(t, file)
+	is.Equal(len(groups), 3)
+	is.Equal(string(groups[0].Title), "Users")
+	is.Equal(groups[0].Details[0].Detail().Key, "{limit}")
+	is.Equal(string(groups[1].Title), "Accounts")
+	is.Equal(strings.TrimSpace(groups[1].Description.String()), "Create and find users.")
+	is.Equal(string(groups[2].Title), "Accounts / Lookup")
+
+	signIn := groups[0].Requests[0]
+	is.Equal(string(signIn.Method), "POST")
+	is.Equal(string(signIn.Path), "/session")
+	is.Equal(len(signIn.Details), 0)
+	is.Equal(len(signIn.Form), 1)
+	is.Equal(signIn.Form[0].Detail().Key, "username")
+	is.Equal(signIn.Form[0].Detail().Value.Data, "{name}")
+
+	upload := groups[0].Requests[1]
+	is.Equal(string(upload.Method), "PUT")
+	is.Equal(string(upload.Path), "/users/{id}/avatar")
+	is.Equal(upload.Details[0].Detail().Value.Data, "Bearer {token}")
+	is.Equal(len(upload.Parts), 2)
+	is.Equal(upload.Parts[1].Detail().Value.Data, "@avatar.png")
+
+	list := groups[1].Requests[0]
+	is.Equal(string(list.Path), "/users")
+	is.Equal(len(list.Params), 1)
+	is.Equal(list.Params[0].Detail().Value.Data, "{limit}")
+	is.Equal(list.ExpectedDetails[0].Detail().Value.Data, 200.0)
+
+	create := groups[1].Requests[1]
+	is.Equal(create.BodyType, "json")
+	is.Equal(create.ExpectedDetails[0].Detail().Value.Data, 201.0)
+
+	get := groups[2].Requests[0]
+	is.Equal(string(get.Path), "/users/2")
+	is.Equal(get.ExpectedDetails[0].Detail().Value.Data, 200.0)
+}
+
+func TestPostmanEnvironment(t *testing.T) {
+	is := is.New(t)
+	b, err := ioutil.ReadFile("../testfiles/success/import/users.postman_environment.json")
+	is.NoErr(err)
+	is.True(convert.IsPostmanEnvironment(b))
+	name, vars, err := convert.PostmanEnvironment(bytes.NewReader(b))
+	is.NoErr(err)
+	is.Equal(name, "Local")
+	is.Equal(len(vars), 2)
+	is.Equal(vars["token"], "secret")
+	is.Equal(vars["name"], "Mat")
+}
+
+func TestPostmanRun(t *testing.T) {
+	is := is.New(t)
+	f, err := os.Open("../testfiles/success/import/users.postman_collection.json")
+	is.NoErr(err)
+	defer f.Close()
+	file, err := convert.Postman("users.postman_collection.json", f)
+	is.NoErr(err)
+	groups := roundTrip(t, file)
+	// skip sign in and upload, which have no server
+	groups[0].Requests = nil
+
+	b, err := ioutil.ReadFile("../testfiles/success/import/users.postman_environment.json")
+	is.NoErr(err)
+	_, vars, err := convert.PostmanEnvironment(bytes.NewReader(b))
+	is.NoErr(err)
+	varsFile, err := ioutil.TempFile("", "silk-vars")
+	is.NoErr(err)
+	defer os.Remove(varsFile.Name())
+	is.NoErr(json.NewEncoder(varsFile).Encode(vars))
+	is.NoErr(varsFile.Close())
+
+	s := httptest.NewServer(testutil.UsersHandler())
+	defer s.Close()
+	r := runner.New(t, s.URL)
+	is.NoErr(r.LoadVars(varsFile.Name()))
+	r.RunGroup(groups...)
+}
+
+func TestHAR(t *testing.T) {
+	is := is.New(t)
+	f, err := os.Open("../testfiles/success/import/users.har")
+	is.NoErr(err)
+	defer f.Close()
+	file, err := convert.HAR("users.har", f, convert.HAROptions{})
+	is.NoErr(err)
+	is.Equal(file.Name, "users.silk.md")
+	groups := roundTrip(t, file)
+	is.Equal(len(groups), 1)
+	group := groups[0]
+	is.True(strings.Contains(group.Description.String(), "http://localhost:8080, https://cdn.example.com"))
+	is.Equal(len(group.Details), 4)
+	is.Equal(group.Details[0].Detail().Key, "{authorization}")
+	is.Equal(group.Details[0].Detail().Value.Data, "REDACTED")
+	is.Equal(group.Details[1].Detail().Key, "{cookie_session}")
+	is.Equal(group.Details[2].Detail().Key, "{cookie_theme}")
+	is.Equal(group.Details[3].Detail().Key, "{x_api_key}")
+	is.Equal(len(group.Requests), 5)
+
+	list := group.Requests[0]
+	is.Equal(string(list.Path), "/users")
+	is.Equal(list.Params[0].Detail().Key, "limit")
+	is.Equal(len(list.Details), 3)
+	is.Equal(list.Details[0].Detail().Key, "Accept")
+	is.Equal(list.Details[1].Detail().Value.Data, "Bearer {authorization}")
+	is.Equal(list.Details[2].Detail().Key, "Cookie")
+	is.Equal(list.Details[2].Detail().Value.Data, "session={cookie_session}; theme={cookie_theme}")
+	is.Equal(list.ExpectedDetails[0].Detail().Value.Data, 200.0)
+	is.Equal(list.ExpectedDetails[1].Detail().Value.Data, "application/json")
+	is.Equal(list.ExpectedBodyType, "json")
+
+	logo := group.Requests[1]
+	is.Equal(len(logo.ExpectedBody), 0)
+
+	create := group.Requests[2]
+	is.Equal(create.BodyType, "json")
+	is.Equal(create.Details[1].Detail().Value.Data, "{x_api_key}")
+	is.Equal(create.ExpectedBody.String(), `{"id":3,"name":"Ryan"}`)
+
+	login := group.Requests[4]
+	is.Equal(len(login.Form), 2)
+	is.Equal(login.ExpectedDetails[0].Detail().Value.Data, 302.0)
+}
+
+func TestHARKeepCookies(t *testing.T) {
+	is := is.New(t)
+	f, err := os.Open("../testfiles/success/import/users.har")
+	is.NoErr(err)
+	defer f.Close()
+	file, err := convert.HAR("users.har", f, convert.HAROptions{KeepCookies: true})
+	is.NoErr(err)
+	groups := roundTrip(t, file)
+	is.Equal(len(groups[0].Details), 2)
+	list := groups[0].Requests[0]
+	is.Equal(list.Details[2].Detail().Value.Data, "session=xyz; theme=dark")
+}
+
+func TestHARFilters(t *testing.T) {
+	is := is.New(t)
+	b, err := ioutil.ReadFile("../testfiles/success/import/users.har")
+	is.NoErr(err)
+	file, err := convert.HAR("users.har", bytes.NewReader(b), convert.HAROptions{Host: "localhost"})
+	is.NoErr(err)
+	is.Equal(len(file.Groups[0].Requests), 4)
+	file, err = convert.HAR("users.har", bytes.NewReader(b), convert.HAROptions{PathPrefix: "/users"})
+	is.NoErr(err)
+	is.Equal(len(file.Groups[0].Requests), 3)
+	file, err = convert.HAR("users.har", bytes.NewReader(b), convert.HAROptions{Host: "localhost:8080", Methods: []string{"get"}})
+	is.NoErr(err)
+	is.Equal(len(file.Groups[0].Requests), 2)
+	is.Equal(len(file.Groups[0].Details), 3)
+}
+
+func TestHARRun(t *testing.T) {
+	is := is.New(t)
+	f, err := os.Open("../testfiles/success/import/users.har")
+	is.NoErr(err)
+	defer f.Close()
+	file, err := convert.HAR("users.har", f, convert.HAROptions{Host: "localhost:8080", PathPrefix: "/users"})
+	is.NoErr(err)
+	groups := roundTrip(t, file)
+	s := httptest.NewServer(testutil.UsersHandler())
+	defer s.Close()
+	r := runner.New(t, s.URL)
+	r.RunGroup(groups...)
+}
@@ -0,0 +1,3 @@
+// Package convert turns requests from other tools into
+// silk documents.
+package convert
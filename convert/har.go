@@ -0,0 +1,289 @@
+package convert
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/matryer/silk/document"
+)
+
+// HAROptions selects the entries of a HAR archive to import.
+type HAROptions struct {
+	// Host only imports requests to this host (like example.com
+	// or localhost:8080), if set.
+	Host string
+	// PathPrefix only imports requests with paths that begin
+	// with this prefix, if set.
+	PathPrefix string
+	// Methods only imports requests with these methods, if set.
+	Methods []string
+	// KeepCookies writes cookie values as they were recorded,
+	// instead of referring to variables.
+	KeepCookies bool
+}
+
+// match gets whether the request should be imported.
+func (o HAROptions) match(method string, u *url.URL) bool {
+	if len(o.Host) > 0 && !strings.EqualFold(u.Host, o.Host) && !strings.EqualFold(u.Hostname(), o.Host) {
+		return false
+	}
+	if !strings.HasPrefix(u.Path, o.PathPrefix) {
+		return false
+	}
+	if len(o.Methods) == 0 {
+		return true
+	}
+	for _, m := range o.Methods {
+		if strings.EqualFold(m, method) {
+			return true
+		}
+	}
+	return false
+}
+
+type harArchive struct {
+	Log struct {
+		Entries []harEntry `json:"entries"`
+	} `json:"log"`
+}
+
+type harEntry struct {
+	Request struct {
+		Method      string       `json:"method"`
+		URL         string       `json:"url"`
+		Headers     []harNameVal `json:"headers"`
+		QueryString []harNameVal `json:"queryString"`
+		Cookies     []harNameVal `json:"cookies"`
+		PostData    *harPostData `json:"postData"`
+	} `json:"request"`
+	Response struct {
+		Status  int          `json:"status"`
+		Headers []harNameVal `json:"headers"`
+		Content struct {
+			MimeType string `json:"mimeType"`
+			Text     string `json:"text"`
+			Encoding string `json:"encoding"`
+		} `json:"content"`
+	} `json:"response"`
+}
+
+type harNameVal struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	FileName string `json:"fileName"`
+}
+
+type harPostData struct {
+	MimeType string       `json:"mimeType"`
+	Text     string       `json:"text"`
+	Params   []harNameVal `json:"params"`
+}
+
+// harDroppedHeaders are request headers that browsers and
+// clients add, which don't describe the request.
+var harDroppedHeaders = map[string]bool{
+	"accept-encoding":           true,
+	"accept-language":           true,
+	"cache-control":             true,
+	"connection":                true,
+	"content-length":            true,
+	"cookie":                    true,
+	"dnt":                       true,
+	"host":                      true,
+	"if-modified-since":         true,
+	"if-none-match":             true,
+	"origin":                    true,
+	"pragma":                    true,
+	"priority":                  true,
+	"referer":                   true,
+	"te":                        true,
+	"upgrade-insecure-requests": true,
+	"user-agent":                true,
+}
+
+// harRedactedHeaders are request headers with secret values,
+// which are replaced with variables.
+var harRedactedHeaders = map[string]bool{
+	"authorization":       true,
+	"proxy-authorization": true,
+	"x-api-key":           true,
+	"x-auth-token":        true,
+	"x-csrf-token":        true,
+	"x-xsrf-token":        true,
+}
+
+// redacted is the value given to the variables of
+// redacted headers.
+const redacted = "REDACTED"
+
+var harVarNameRegexp = regexp.MustCompile(`[^a-z0-9]+`)
+
+// harVarName gets the name of the variable for a lower case
+// header or cookie name, like x_api_key for x-api-key.
+func harVarName(name string) string {
+	return strings.Trim(harVarNameRegexp.ReplaceAllString(name, "_"), "_")
+}
+
+// HAR converts the entries of a HAR archive, as exported by browser
+// developer tools and many proxies, into a silk document.
+// Each request asserts the recorded status, Content-Type and
+// JSON body.
+// Noisy headers (like User-Agent) are dropped, and secret ones (like
+// Authorization) refer to variables. Cookies become a Cookie detail,
+// with values that refer to variables unless options.KeepCookies
+// is set.
+func HAR(filename string, r io.Reader, options HAROptions) (*document.File, error) {
+	var archive harArchive
+	if err := json.NewDecoder(r).Decode(&archive); err != nil {
+		return nil, err
+	}
+	if archive.Log.Entries == nil {
+		return nil, errors.New("not a HAR archive")
+	}
+	base := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	group := &document.Group{Title: base}
+	var origins, secrets []string
+	for _, entry := range archive.Log.Entries {
+		u, err := url.Parse(entry.Request.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			continue
+		}
+		method := strings.ToUpper(entry.Request.Method)
+		if !options.match(method, u) {
+			continue
+		}
+		origin := u.Scheme + "://" + u.Host
+		if !containsString(origins, origin) {
+			origins = append(origins, origin)
+		}
+		req, names := harRequest(method, u, entry, options.KeepCookies)
+		for _, name := range names {
+			if !containsString(secrets, name) {
+				secrets = append(secrets, name)
+			}
+		}
+		group.Requests = append(group.Requests, req)
+	}
+	for _, name := range secrets {
+		group.Details = append(group.Details, document.Field{Key: "{" + name + "}", Value: redacted, Comment: "set with -silk.vars"})
+	}
+	group.Description = "Imported from " + filepath.Base(filename) + "."
+	if len(origins) > 0 {
+		group.Description += "\nRequests were made to " + strings.Join(origins, ", ") + "."
+	}
+	return &document.File{
+		Name:   base + ".silk.md",
+		Groups: []*document.Group{group},
+	}, nil
+}
+
+// harRequest makes a silk request from the entry, and gets the
+// names of the variables used for redacted headers and cookies.
+func harRequest(method string, u *url.URL, entry harEntry, keepCookies bool) (*document.Request, []string) {
+	req := &document.Request{Method: method, Path: ensureSlash(u.Path)}
+	if entry.Request.QueryString != nil {
+		for _, q := range entry.Request.QueryString {
+			req.Params = append(req.Params, document.Field{Key: q.Name, Value: q.Value})
+		}
+	} else {
+		for _, param := range strings.Split(u.RawQuery, "&") {
+			if len(param) == 0 {
+				continue
+			}
+			kv := strings.SplitN(param, "=", 2)
+			key, _ := url.QueryUnescape(kv[0])
+			var val string
+			if len(kv) > 1 {
+				val, _ = url.QueryUnescape(kv[1])
+			}
+			req.Params = append(req.Params, document.Field{Key: key, Value: val})
+		}
+	}
+	var secrets []string
+	for _, h := range entry.Request.Headers {
+		name := strings.ToLower(h.Name)
+		if strings.HasPrefix(name, ":") || strings.HasPrefix(name, "sec-") || harDroppedHeaders[name] {
+			continue
+		}
+		value := h.Value
+		if harRedactedHeaders[name] {
+			v := harVarName(name)
+			secrets = append(secrets, v)
+			value = "{" + v + "}"
+			if i := strings.Index(h.Value, " "); i > -1 && name == "authorization" {
+				// keep the scheme, like Bearer
+				value = h.Value[:i+1] + value
+			}
+		}
+		req.Details = append(req.Details, document.Field{Key: h.Name, Value: value})
+	}
+	if len(entry.Request.Cookies) > 0 {
+		var cookies []string
+		for _, c := range entry.Request.Cookies {
+			value := c.Value
+			if !keepCookies {
+				v := harVarName("cookie_" + strings.ToLower(c.Name))
+				secrets = append(secrets, v)
+				value = "{" + v + "}"
+			}
+			cookies = append(cookies, c.Name+"="+value)
+		}
+		req.Details = append(req.Details, document.Field{Key: "Cookie", Value: strings.Join(cookies, "; ")})
+	}
+	if post := entry.Request.PostData; post != nil {
+		switch {
+		case strings.HasPrefix(post.MimeType, "application/x-www-form-urlencoded") && len(post.Params) > 0:
+			for _, p := range post.Params {
+				req.Form = append(req.Form, document.Field{Key: p.Name, Value: p.Value})
+			}
+		case strings.HasPrefix(post.MimeType, "multipart/form-data") && len(post.Params) > 0:
+			for _, p := range post.Params {
+				value := p.Value
+				if len(p.FileName) > 0 {
+					value = "@" + p.FileName
+				}
+				req.Parts = append(req.Parts, document.Field{Key: p.Name, Value: value})
+			}
+		case len(post.Text) > 0:
+			req.Body = post.Text
+			if strings.Contains(post.MimeType, "json") {
+				req.BodyType = "json"
+			}
+		}
+	}
+	res := entry.Response
+	if res.Status > 0 {
+		req.ExpectedDetails = append(req.ExpectedDetails, document.Field{Key: "Status", Value: res.Status})
+	}
+	contentType := res.Content.MimeType
+	for _, h := range res.Headers {
+		if strings.EqualFold(h.Name, "Content-Type") {
+			contentType = h.Value
+		}
+	}
+	if len(contentType) > 0 {
+		req.ExpectedDetails = append(req.ExpectedDetails, document.Field{Key: "Content-Type", Value: contentType})
+	}
+	body := res.Content.Text
+	if res.Content.Encoding == "base64" {
+		b, err := base64.StdEncoding.DecodeString(body)
+		if err != nil {
+			body = ""
+		} else {
+			body = string(b)
+		}
+	}
+	// other bodies (like HTML) must match exactly, which
+	// rarely holds, so only JSON is asserted
+	if strings.Contains(contentType, "json") {
+		req.ExpectedBody = strings.TrimSpace(body)
+		req.ExpectedBodyType = "json"
+	}
+	return req, secrets
+}
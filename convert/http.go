@@ -0,0 +1,265 @@
+package convert
+
+import (
+	"bufio"
+	"io"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/matryer/silk/document"
+)
+
+// HTTP converts a .http (or .rest) file, as used by the JetBrains
+// HTTP client and VS Code REST Client, into a silk document.
+// Requests are separated by ### lines, {{var}} references become
+// {var} and @name = value declarations become group variables.
+func HTTP(filename string, r io.Reader) (*document.File, error) {
+	base := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	group := &document.Group{Title: base}
+	var origins []string
+	p := &httpParser{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		p.line(scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	p.flush()
+	for _, v := range p.vars {
+		group.Details = append(group.Details, document.Field{Key: "{" + v.name + "}", Value: v.value})
+	}
+	for _, hr := range p.requests {
+		req, origin := hr.request()
+		if len(origin) > 0 && !containsString(origins, origin) {
+			origins = append(origins, origin)
+		}
+		group.Requests = append(group.Requests, req)
+	}
+	group.Description = "Imported from " + filepath.Base(filename) + "."
+	if len(origins) > 0 {
+		group.Description += "\nRequests were made to " + strings.Join(origins, ", ") + "."
+	}
+	return &document.File{
+		Name:   base + ".silk.md",
+		Groups: []*document.Group{group},
+	}, nil
+}
+
+type httpVar struct {
+	name  string
+	value string
+}
+
+// httpRequest is a request read from a .http file.
+type httpRequest struct {
+	title   string
+	comment []string
+	method  string
+	url     string
+	headers [][2]string
+	body    []string
+	script  []string
+}
+
+type httpState int
+
+const (
+	stateStart httpState = iota
+	stateURL
+	stateHeaders
+	stateBody
+	stateScript
+)
+
+type httpParser struct {
+	vars     []httpVar
+	requests []*httpRequest
+	current  *httpRequest
+	state    httpState
+}
+
+var (
+	httpVarRegexp     = regexp.MustCompile(`^@([A-Za-z0-9_.-]+)\s*=\s*(.*)$`)
+	httpRequestRegexp = regexp.MustCompile(`(?i)^(GET|POST|PUT|PATCH|DELETE|HEAD|OPTIONS|TRACE|CONNECT)\s+(\S+)(\s+HTTP/[0-9.]+)?\s*$`)
+	httpURLRegexp     = regexp.MustCompile(`^(https?://|/|{{)\S*(\s+HTTP/[0-9.]+)?\s*$`)
+	httpHeaderRegexp  = regexp.MustCompile(`^([A-Za-z0-9!#$%&'*+.^_|~-]+):\s*(.*)$`)
+	httpNameRegexp    = regexp.MustCompile(`^(#|//)\s*@name\s+(.+)$`)
+)
+
+func (p *httpParser) line(line string) {
+	trimmed := strings.TrimSpace(line)
+	if strings.HasPrefix(trimmed, "###") {
+		p.flush()
+		p.current = &httpRequest{title: strings.TrimSpace(strings.TrimLeft(trimmed, "#"))}
+		p.state = stateStart
+		return
+	}
+	switch p.state {
+	case stateStart:
+		switch {
+		case len(trimmed) == 0:
+		case httpVarRegexp.MatchString(trimmed):
+			m := httpVarRegexp.FindStringSubmatch(trimmed)
+			p.vars = append(p.vars, httpVar{name: m[1], value: vars(strings.TrimSpace(m[2]))})
+		case httpNameRegexp.MatchString(trimmed):
+			p.request().comment = append(p.request().comment, "Named "+httpNameRegexp.FindStringSubmatch(trimmed)[2]+".")
+		case strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "//"):
+			p.request().comment = append(p.request().comment, strings.TrimSpace(strings.TrimLeft(trimmed, "#/")))
+		case httpRequestRegexp.MatchString(trimmed):
+			m := httpRequestRegexp.FindStringSubmatch(trimmed)
+			p.request().method, p.request().url = strings.ToUpper(m[1]), m[2]
+			p.state = stateURL
+		case httpURLRegexp.MatchString(trimmed):
+			// the method defaults to GET
+			p.request().method, p.request().url = "GET", strings.Fields(trimmed)[0]
+			p.state = stateURL
+		}
+	case stateURL:
+		// query parameters may continue on the following lines
+		if strings.HasPrefix(trimmed, "?") || strings.HasPrefix(trimmed, "&") {
+			p.current.url += trimmed
+			return
+		}
+		p.state = stateHeaders
+		p.line(line)
+	case stateHeaders:
+		switch {
+		case len(trimmed) == 0:
+			p.state = stateBody
+		case strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "//"):
+		case httpHeaderRegexp.MatchString(trimmed):
+			m := httpHeaderRegexp.FindStringSubmatch(trimmed)
+			p.current.headers = append(p.current.headers, [2]string{m[1], vars(m[2])})
+		}
+	case stateBody:
+		switch {
+		case strings.HasPrefix(trimmed, "> {%"):
+			p.state = stateScript
+			p.scriptLine(strings.TrimPrefix(trimmed, "> {%"))
+		case strings.HasPrefix(trimmed, "<> "), strings.HasPrefix(trimmed, ">> "), strings.HasPrefix(trimmed, ">>! "):
+			// response references and redirects are ignored
+		default:
+			p.current.body = append(p.current.body, line)
+		}
+	case stateScript:
+		p.scriptLine(line)
+	}
+}
+
+func (p *httpParser) scriptLine(line string) {
+	if i := strings.Index(line, "%}"); i > -1 {
+		p.current.script = append(p.current.script, line[:i])
+		p.state = stateBody
+		return
+	}
+	p.current.script = append(p.current.script, line)
+}
+
+// request gets the current request, starting one if needed.
+func (p *httpParser) request() *httpRequest {
+	if p.current == nil {
+		p.current = &httpRequest{}
+	}
+	return p.current
+}
+
+// flush finishes the current request.
+func (p *httpParser) flush() {
+	if p.current != nil && len(p.current.method) > 0 {
+		p.requests = append(p.requests, p.current)
+	}
+	p.current = nil
+	p.state = stateStart
+}
+
+var httpStatusRegexp = regexp.MustCompile(`response\.status\s*===?\s*([0-9]{3})`)
+
+// request makes a silk request, and gets the origin (scheme and
+// host) that the request was made to.
+func (hr *httpRequest) request() (*document.Request, string) {
+	req := &document.Request{Method: hr.method}
+	origin, path := splitOrigin(vars(hr.url))
+	if i := strings.Index(path, "?"); i > -1 {
+		for _, param := range strings.Split(path[i+1:], "&") {
+			if len(param) == 0 {
+				continue
+			}
+			kv := strings.SplitN(param, "=", 2)
+			key, _ := url.QueryUnescape(kv[0])
+			var val string
+			if len(kv) > 1 {
+				val, _ = url.QueryUnescape(kv[1])
+			}
+			req.Params = append(req.Params, document.Field{Key: key, Value: val})
+		}
+		path = path[:i]
+	}
+	req.Path = path
+	req.Description = strings.TrimSpace(hr.title + "\n\n" + strings.Join(hr.comment, "\n"))
+	contentType := ""
+	for _, h := range hr.headers {
+		if strings.EqualFold(h[0], "Content-Type") {
+			contentType = h[1]
+		}
+		req.Details = append(req.Details, document.Field{Key: h[0], Value: h[1]})
+	}
+	body := strings.TrimSpace(strings.Join(hr.body, "\n"))
+	if strings.HasPrefix(body, "< ") && !strings.Contains(body, "\n") {
+		// < ./path/to/file
+		req.Details = append(req.Details, document.Field{Key: "Body", Value: document.FileRef(strings.TrimSpace(body[2:]))})
+	} else if len(body) > 0 {
+		req.Body = vars(body)
+		if strings.Contains(contentType, "json") {
+			req.BodyType = "json"
+		}
+	}
+	if m := httpStatusRegexp.FindStringSubmatch(strings.Join(hr.script, "\n")); m != nil {
+		status, _ := strconv.Atoi(m[1])
+		req.ExpectedDetails = append(req.ExpectedDetails, document.Field{Key: "Status", Value: status})
+	}
+	return req, origin
+}
+
+var httpVarRefRegexp = regexp.MustCompile(`{{\s*([^{}\s]+)\s*}}`)
+
+// vars turns {{var}} references into silk {var} references.
+func vars(s string) string {
+	return httpVarRefRegexp.ReplaceAllString(s, "{$1}")
+}
+
+// splitOrigin splits a URL into the origin (like https://host or
+// a {base} variable) and the path.
+func splitOrigin(u string) (string, string) {
+	rest := u
+	prefix := ""
+	if i := strings.Index(u, "://"); i > -1 {
+		prefix, rest = u[:i+3], u[i+3:]
+	} else if !strings.HasPrefix(u, "{") {
+		return "", ensureSlash(u)
+	}
+	i := strings.IndexAny(rest, "/?")
+	if i == -1 {
+		return prefix + rest, "/"
+	}
+	return prefix + rest[:i], ensureSlash(rest[i:])
+}
+
+func ensureSlash(path string) string {
+	if !strings.HasPrefix(path, "/") {
+		return "/" + path
+	}
+	return path
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,386 @@
+package convert
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/matryer/silk/document"
+)
+
+// postmanCollection is a Postman Collection (v2.1).
+type postmanCollection struct {
+	Info struct {
+		Name        string             `json:"name"`
+		Description postmanDescription `json:"description"`
+		Schema      string             `json:"schema"`
+	} `json:"info"`
+	Item     []*postmanItem  `json:"item"`
+	Variable []postmanKeyVal `json:"variable"`
+	Auth     *postmanAuth    `json:"auth"`
+	Event    []postmanEvent  `json:"event"`
+	// Name and Values are used by environments.
+	Name   string          `json:"name"`
+	Values []postmanKeyVal `json:"values"`
+}
+
+type postmanItem struct {
+	Name        string             `json:"name"`
+	Description postmanDescription `json:"description"`
+	Item        []*postmanItem     `json:"item"`
+	Request     *postmanRequest    `json:"request"`
+	Event       []postmanEvent     `json:"event"`
+	Auth        *postmanAuth       `json:"auth"`
+}
+
+type postmanRequest struct {
+	Method      string             `json:"method"`
+	Header      []postmanKeyVal    `json:"header"`
+	URL         postmanURL         `json:"url"`
+	Body        *postmanBody       `json:"body"`
+	Auth        *postmanAuth       `json:"auth"`
+	Description postmanDescription `json:"description"`
+}
+
+type postmanKeyVal struct {
+	Key      string      `json:"key"`
+	Value    interface{} `json:"value"`
+	Disabled bool        `json:"disabled"`
+	// Enabled is used by environments.
+	Enabled *bool       `json:"enabled"`
+	Type    string      `json:"type"`
+	Src     interface{} `json:"src"`
+}
+
+func (kv postmanKeyVal) value() string {
+	if kv.Value == nil {
+		return ""
+	}
+	if s, ok := kv.Value.(string); ok {
+		return s
+	}
+	return fmt.Sprint(kv.Value)
+}
+
+// postmanURL is a URL, which may be a string or an object.
+type postmanURL struct {
+	Raw      string          `json:"raw"`
+	Path     interface{}     `json:"path"`
+	Query    []postmanKeyVal `json:"query"`
+	Variable []postmanKeyVal `json:"variable"`
+}
+
+func (u *postmanURL) UnmarshalJSON(b []byte) error {
+	var raw string
+	if err := json.Unmarshal(b, &raw); err == nil {
+		u.Raw = raw
+		return nil
+	}
+	type plain postmanURL
+	return json.Unmarshal(b, (*plain)(u))
+}
+
+// postmanDescription is a description, which may be a string
+// or an object with content.
+type postmanDescription string
+
+func (d *postmanDescription) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*d = postmanDescription(s)
+		return nil
+	}
+	var obj struct {
+		Content string `json:"content"`
+	}
+	if err := json.Unmarshal(b, &obj); err != nil {
+		return err
+	}
+	*d = postmanDescription(obj.Content)
+	return nil
+}
+
+type postmanBody struct {
+	Mode       string          `json:"mode"`
+	Raw        string          `json:"raw"`
+	URLEncoded []postmanKeyVal `json:"urlencoded"`
+	FormData   []postmanKeyVal `json:"formdata"`
+	File       struct {
+		Src string `json:"src"`
+	} `json:"file"`
+	Options struct {
+		Raw struct {
+			Language string `json:"language"`
+		} `json:"raw"`
+	} `json:"options"`
+	Disabled bool `json:"disabled"`
+}
+
+type postmanAuth struct {
+	Type   string          `json:"type"`
+	Bearer []postmanKeyVal `json:"bearer"`
+}
+
+type postmanEvent struct {
+	Listen string `json:"listen"`
+	Script struct {
+		Exec interface{} `json:"exec"`
+	} `json:"script"`
+}
+
+// lines gets the lines of the script, which may be a
+// string or a list of strings.
+func (e postmanEvent) lines() string {
+	switch exec := e.Script.Exec.(type) {
+	case string:
+		return exec
+	case []interface{}:
+		var lines []string
+		for _, line := range exec {
+			lines = append(lines, fmt.Sprint(line))
+		}
+		return strings.Join(lines, "\n")
+	}
+	return ""
+}
+
+// IsPostmanEnvironment gets whether the JSON is a Postman
+// environment, rather than a collection.
+func IsPostmanEnvironment(b []byte) bool {
+	var c postmanCollection
+	if err := json.Unmarshal(b, &c); err != nil {
+		return false
+	}
+	return c.Values != nil && c.Item == nil
+}
+
+// PostmanEnvironment converts a Postman environment into
+// variables, as loaded by runner.LoadVars, and gets its name.
+// Disabled variables are skipped.
+func PostmanEnvironment(r io.Reader) (string, map[string]interface{}, error) {
+	var env postmanCollection
+	if err := json.NewDecoder(r).Decode(&env); err != nil {
+		return "", nil, err
+	}
+	if env.Values == nil {
+		return "", nil, errors.New("not a Postman environment")
+	}
+	vars := make(map[string]interface{})
+	for _, v := range env.Values {
+		if v.Disabled || (v.Enabled != nil && !*v.Enabled) {
+			continue
+		}
+		vars[v.Key] = v.Value
+	}
+	return env.Name, vars, nil
+}
+
+// Postman converts a Postman Collection (v2.1) into a silk document.
+// Folders become groups, and simple status checks in test scripts
+// become Status assertions.
+// Collection variables are declared in the first group.
+func Postman(filename string, r io.Reader) (*document.File, error) {
+	var c postmanCollection
+	if err := json.NewDecoder(r).Decode(&c); err != nil {
+		return nil, err
+	}
+	if c.Item == nil {
+		return nil, errors.New("not a Postman collection")
+	}
+	if len(c.Info.Schema) > 0 && !strings.Contains(c.Info.Schema, "v2.") {
+		return nil, fmt.Errorf("unsupported collection schema %s", c.Info.Schema)
+	}
+	root := &document.Group{
+		Title:       c.Info.Name,
+		Description: string(c.Info.Description),
+	}
+	groups := []*document.Group{root}
+	var walk func(prefix string, items []*postmanItem, group *document.Group, auth *postmanAuth, events []postmanEvent)
+	walk = func(prefix string, items []*postmanItem, group *document.Group, auth *postmanAuth, events []postmanEvent) {
+		for _, item := range items {
+			itemAuth := auth
+			if item.Auth != nil {
+				itemAuth = item.Auth
+			}
+			itemEvents := append(append([]postmanEvent{}, events...), item.Event...)
+			if item.Request == nil {
+				// folder
+				title := item.Name
+				if len(prefix) > 0 {
+					title = prefix + " / " + item.Name
+				}
+				folder := &document.Group{Title: title, Description: string(item.Description)}
+				groups = append(groups, folder)
+				walk(title, item.Item, folder, itemAuth, itemEvents)
+				continue
+			}
+			group.Requests = append(group.Requests, postmanRequestFor(item, itemAuth, itemEvents))
+		}
+	}
+	walk("", c.Item, root, c.Auth, c.Event)
+	if len(root.Requests) == 0 && len(groups) > 1 {
+		// no requests outside folders
+		groups = groups[1:]
+		groups[0].Description = strings.TrimSpace(string(c.Info.Description) + "\n\n" + groups[0].Description)
+	}
+	for _, v := range c.Variable {
+		if v.Disabled {
+			continue
+		}
+		groups[0].Details = append(groups[0].Details, document.Field{Key: "{" + v.Key + "}", Value: vars(v.value())})
+	}
+	base := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	base = strings.TrimSuffix(base, ".postman_collection")
+	return &document.File{
+		Name:   base + ".silk.md",
+		Groups: groups,
+	}, nil
+}
+
+var postmanPathVarRegexp = regexp.MustCompile(`(^|/):([A-Za-z0-9_]+)`)
+
+func postmanRequestFor(item *postmanItem, auth *postmanAuth, events []postmanEvent) *document.Request {
+	pr := item.Request
+	method := strings.ToUpper(pr.Method)
+	if len(method) == 0 {
+		method = "GET"
+	}
+	req := &document.Request{
+		Method:      method,
+		Description: strings.TrimSpace(item.Name + "\n\n" + string(pr.Description)),
+	}
+	raw := vars(pr.URL.Raw)
+	_, path := splitOrigin(raw)
+	if i := strings.Index(path, "?"); i > -1 {
+		path = path[:i]
+	}
+	if i := strings.Index(path, "#"); i > -1 {
+		path = path[:i]
+	}
+	// :id path variables
+	pathVars := make(map[string]string)
+	for _, v := range pr.URL.Variable {
+		pathVars[v.Key] = vars(v.value())
+	}
+	req.Path = postmanPathVarRegexp.ReplaceAllStringFunc(path, func(s string) string {
+		m := postmanPathVarRegexp.FindStringSubmatch(s)
+		if v, ok := pathVars[m[2]]; ok && len(v) > 0 {
+			return m[1] + v
+		}
+		return m[1] + "{" + m[2] + "}"
+	})
+	if pr.URL.Query != nil {
+		for _, q := range pr.URL.Query {
+			if !q.Disabled {
+				req.Params = append(req.Params, document.Field{Key: q.Key, Value: vars(q.value())})
+			}
+		}
+	} else if i := strings.Index(raw, "?"); i > -1 {
+		for _, param := range strings.Split(raw[i+1:], "&") {
+			if len(param) == 0 {
+				continue
+			}
+			kv := strings.SplitN(param, "=", 2)
+			key, _ := url.QueryUnescape(kv[0])
+			var val string
+			if len(kv) > 1 {
+				val, _ = url.QueryUnescape(kv[1])
+			}
+			req.Params = append(req.Params, document.Field{Key: key, Value: val})
+		}
+	}
+	contentType := ""
+	for _, h := range pr.Header {
+		if h.Disabled {
+			continue
+		}
+		if strings.EqualFold(h.Key, "Content-Type") {
+			contentType = h.value()
+		}
+		req.Details = append(req.Details, document.Field{Key: h.Key, Value: vars(h.value())})
+	}
+	if pr.Auth != nil {
+		auth = pr.Auth
+	}
+	if auth != nil && auth.Type == "bearer" {
+		for _, kv := range auth.Bearer {
+			if kv.Key == "token" {
+				req.Details = append(req.Details, document.Field{Key: "Authorization", Value: "Bearer " + vars(kv.value())})
+			}
+		}
+	}
+	if body := pr.Body; body != nil && !body.Disabled {
+		switch body.Mode {
+		case "raw":
+			req.Body = vars(body.Raw)
+			if body.Options.Raw.Language == "json" || strings.Contains(contentType, "json") {
+				req.BodyType = "json"
+				if len(contentType) == 0 {
+					req.Details = append(req.Details, document.Field{Key: "Content-Type", Value: "application/json"})
+				}
+			}
+		case "urlencoded":
+			for _, f := range body.URLEncoded {
+				if !f.Disabled {
+					req.Form = append(req.Form, document.Field{Key: f.Key, Value: vars(f.value())})
+				}
+			}
+		case "formdata":
+			for _, f := range body.FormData {
+				if f.Disabled {
+					continue
+				}
+				value := vars(f.value())
+				if f.Type == "file" {
+					value = "@" + fmt.Sprint(firstSrc(f.Src))
+				}
+				req.Parts = append(req.Parts, document.Field{Key: f.Key, Value: value})
+			}
+		case "file":
+			if len(body.File.Src) > 0 {
+				req.Details = append(req.Details, document.Field{Key: "Body", Value: document.FileRef(body.File.Src)})
+			}
+		}
+	}
+	for _, e := range events {
+		if e.Listen != "test" {
+			continue
+		}
+		if status, ok := postmanStatus(e.lines()); ok {
+			req.ExpectedDetails = []document.Field{{Key: "Status", Value: status}}
+		}
+	}
+	return req
+}
+
+// firstSrc gets the file source of a form field, which
+// may be a list.
+func firstSrc(src interface{}) interface{} {
+	if list, ok := src.([]interface{}); ok && len(list) > 0 {
+		return list[0]
+	}
+	return src
+}
+
+var postmanStatusRegexps = []*regexp.Regexp{
+	regexp.MustCompile(`pm\.response\.to\.have\.status\(\s*([0-9]{3})\s*\)`),
+	regexp.MustCompile(`pm\.expect\(\s*pm\.response\.code\s*\)\.to\.(?:eql|equal|be\.equal)\(\s*([0-9]{3})\s*\)`),
+	regexp.MustCompile(`responseCode\.code\s*===?\s*([0-9]{3})`),
+}
+
+// postmanStatus finds a simple status check in a test script.
+func postmanStatus(script string) (int, bool) {
+	for _, re := range postmanStatusRegexps {
+		if m := re.FindStringSubmatch(script); m != nil {
+			status, err := strconv.Atoi(m[1])
+			return status, err == nil
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,265 @@
+package coverage
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/matryer/silk/openapi"
+)
+
+// Load loads the operations to report on from an OpenAPI spec
+// (a .json, .yaml or .yml file) or a route list.
+func Load(path string) (*openapi.Spec, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".yaml", ".yml":
+		return openapi.Load(path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseRoutes(path, f)
+}
+
+var routeRegexp = regexp.MustCompile(`^([A-Za-z]+)\s+(/\S*)((?:\s+[0-9xX]{3}|\s+default)*)\s*$`)
+
+// ParseRoutes parses a route list, with a method, path and
+// optionally the documented statuses on each line:
+//
+//	# users
+//	GET /users 200
+//	GET /users/{id} 200 404
+//	DELETE /users/{id} 204 4XX
+//
+// Routes without statuses document any response.
+func ParseRoutes(filename string, r io.Reader) (*openapi.Spec, error) {
+	paths := make(map[string]map[string]interface{})
+	scanner := bufio.NewScanner(r)
+	n := 0
+	for scanner.Scan() {
+		n++
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		matches := routeRegexp.FindStringSubmatch(line)
+		if matches == nil {
+			return nil, fmt.Errorf("%s:%d: expected METHOD /path [statuses...]", filename, n)
+		}
+		responses := make(map[string]interface{})
+		for _, status := range strings.Fields(matches[3]) {
+			if status != "default" {
+				status = strings.ToUpper(status)
+			}
+			responses[status] = map[string]interface{}{"description": status}
+		}
+		if len(responses) == 0 {
+			responses["default"] = map[string]interface{}{"description": "any"}
+		}
+		path := matches[2]
+		if paths[path] == nil {
+			paths[path] = make(map[string]interface{})
+		}
+		paths[path][strings.ToLower(matches[1])] = map[string]interface{}{"responses": responses}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(map[string]interface{}{
+		"openapi": "3.0.3",
+		"paths":   paths,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return openapi.Parse(b, filename)
+}
+
+// Report records the requests made to the operations of a spec.
+// It is safe for concurrent use.
+type Report struct {
+	spec      *openapi.Spec
+	lock      sync.Mutex
+	hits      map[*openapi.Operation]int
+	statuses  map[*openapi.Operation]map[int]bool
+	unmatched map[string]int
+}
+
+// New makes a Report for the operations in the spec.
+func New(spec *openapi.Spec) *Report {
+	return &Report{
+		spec:      spec,
+		hits:      make(map[*openapi.Operation]int),
+		statuses:  make(map[*openapi.Operation]map[int]bool),
+		unmatched: make(map[string]int),
+	}
+}
+
+// Record records a request, and the status of its response.
+func (r *Report) Record(method, path string, status int) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	op := r.spec.Find(method, path)
+	if op == nil {
+		r.unmatched[strings.ToUpper(method)+" "+path]++
+		return
+	}
+	r.hits[op]++
+	if r.statuses[op] == nil {
+		r.statuses[op] = make(map[int]bool)
+	}
+	r.statuses[op][status] = true
+}
+
+// Summary is the result of a Report.
+type Summary struct {
+	Operations []Operation `json:"operations"`
+	// Covered is the number of operations with at least one request.
+	Covered int `json:"covered"`
+	Total   int `json:"total"`
+	// Percent is the percentage of operations covered.
+	Percent float64 `json:"percent"`
+	// ResponsesSeen is the number of documented responses seen.
+	ResponsesSeen  int `json:"responsesSeen"`
+	ResponsesTotal int `json:"responsesTotal"`
+	// Unmatched are requests (like GET /path) that did not match
+	// any operation.
+	Unmatched []string `json:"unmatched,omitempty"`
+}
+
+// Operation is the coverage of a single operation.
+type Operation struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Hits   int    `json:"hits"`
+	// Statuses are the response statuses that were seen.
+	Statuses []int `json:"statuses"`
+	// Unseen are the documented responses (like 404, 4XX or
+	// default) that were never seen.
+	Unseen []string `json:"unseen"`
+}
+
+// Summary summarizes the report.
+func (r *Report) Summary() Summary {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	s := Summary{
+		Operations: []Operation{},
+		Total:      len(r.spec.Operations),
+	}
+	for _, op := range r.spec.Operations {
+		o := Operation{
+			Method:   op.Method,
+			Path:     op.Path,
+			Hits:     r.hits[op],
+			Statuses: []int{},
+			Unseen:   []string{},
+		}
+		for status := range r.statuses[op] {
+			o.Statuses = append(o.Statuses, status)
+		}
+		sort.Ints(o.Statuses)
+		codes := op.Responses()
+		for _, code := range codes {
+			s.ResponsesTotal++
+			if seen(code, codes, o.Statuses) {
+				s.ResponsesSeen++
+			} else {
+				o.Unseen = append(o.Unseen, code)
+			}
+		}
+		if o.Hits > 0 {
+			s.Covered++
+		}
+		s.Operations = append(s.Operations, o)
+	}
+	if s.Total > 0 {
+		s.Percent = 100 * float64(s.Covered) / float64(s.Total)
+	}
+	for request := range r.unmatched {
+		s.Unmatched = append(s.Unmatched, request)
+	}
+	sort.Strings(s.Unmatched)
+	return s
+}
+
+// seen gets whether any of the statuses are documented by code,
+// which may be a status, a range like 4XX or the default for
+// statuses that are not otherwise documented.
+func seen(code string, codes []string, statuses []int) bool {
+	for _, status := range statuses {
+		if documents(code, status) {
+			if code != "default" {
+				return true
+			}
+			documented := false
+			for _, other := range codes {
+				if other != "default" && documents(other, status) {
+					documented = true
+					break
+				}
+			}
+			if !documented {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func documents(code string, status int) bool {
+	s := strconv.Itoa(status)
+	switch {
+	case code == "default":
+		return true
+	case strings.HasSuffix(strings.ToUpper(code), "XX"):
+		return s[:1] == code[:1]
+	}
+	return s == code
+}
+
+// WriteText writes the summary as a table.
+func (s Summary) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "METHOD\tPATH\tHITS\tSTATUSES\tUNSEEN")
+	for _, o := range s.Operations {
+		statuses := make([]string, len(o.Statuses))
+		for i, status := range o.Statuses {
+			statuses[i] = strconv.Itoa(status)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\n", o.Method, o.Path, o.Hits, list(statuses), list(o.Unseen))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "\ncoverage: %d/%d operations (%.1f%%), %d/%d responses\n", s.Covered, s.Total, s.Percent, s.ResponsesSeen, s.ResponsesTotal)
+	for _, request := range s.Unmatched {
+		fmt.Fprintln(w, "unmatched:", request)
+	}
+	return nil
+}
+
+// WriteJSON writes the summary as JSON.
+func (s Summary) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+func list(items []string) string {
+	if len(items) == 0 {
+		return "-"
+	}
+	return strings.Join(items, ", ")
+}
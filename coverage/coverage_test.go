@@ -0,0 +1,88 @@
+package coverage_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/cheekybits/is"
+	"github.com/matryer/silk/coverage"
+)
+
+func TestParseRoutes(t *testing.T) {
+	is := is.New(t)
+	spec, err := coverage.Load("../testfiles/success/routes.txt")
+	is.NoErr(err)
+	is.Equal(len(spec.Operations), 5)
+	op := spec.Find("DELETE", "/users/1")
+	is.NotNil(op)
+	is.Equal(strings.Join(op.Responses(), ","), "204,4XX")
+	op = spec.Find("PUT", "/users/1")
+	is.NotNil(op)
+	is.Equal(strings.Join(op.Responses(), ","), "default")
+
+	_, err = coverage.ParseRoutes("bad.txt", strings.NewReader("GET /users\nnot a route\n"))
+	is.Err(err)
+	is.Equal(err.Error(), "bad.txt:2: expected METHOD /path [statuses...]")
+}
+
+func TestReport(t *testing.T) {
+	is := is.New(t)
+	spec, err := coverage.Load("../testfiles/success/openapi.yaml")
+	is.NoErr(err)
+	report := coverage.New(spec)
+	report.Record("GET", "/users", 200)
+	report.Record("GET", "/v1/users", 200)
+	report.Record("GET", "/users/1", 200)
+	report.Record("DELETE", "/users/1", 404)
+	report.Record("GET", "/nope", 404)
+
+	s := report.Summary()
+	is.Equal(s.Total, 4)
+	is.Equal(s.Covered, 3)
+	is.Equal(s.Percent, 75.0)
+	is.Equal(s.ResponsesTotal, 7)
+	is.Equal(s.ResponsesSeen, 3)
+	is.Equal(len(s.Unmatched), 1)
+	is.Equal(s.Unmatched[0], "GET /nope")
+
+	list := s.Operations[0]
+	is.Equal(list.Method, "GET")
+	is.Equal(list.Path, "/users")
+	is.Equal(list.Hits, 2)
+	is.Equal(len(list.Statuses), 1)
+	is.Equal(len(list.Unseen), 0)
+
+	create := s.Operations[1]
+	is.Equal(create.Hits, 0)
+	is.Equal(strings.Join(create.Unseen, ","), "201,400")
+
+	del := s.Operations[3]
+	is.Equal(del.Method, "DELETE")
+	is.Equal(strings.Join(del.Unseen, ","), "204")
+
+	var buf bytes.Buffer
+	is.NoErr(s.WriteText(&buf))
+	text := buf.String()
+	is.True(strings.Contains(text, "coverage: 3/4 operations (75.0%), 3/7 responses"))
+	is.True(strings.Contains(text, "unmatched: GET /nope"))
+
+	buf.Reset()
+	is.NoErr(s.WriteJSON(&buf))
+	var decoded coverage.Summary
+	is.NoErr(json.Unmarshal(buf.Bytes(), &decoded))
+	is.Equal(decoded.Covered, 3)
+	is.Equal(len(decoded.Operations), 4)
+}
+
+func TestSeenDefault(t *testing.T) {
+	is := is.New(t)
+	spec, err := coverage.ParseRoutes("routes.txt", strings.NewReader("GET /a 200 default\n"))
+	is.NoErr(err)
+	report := coverage.New(spec)
+	report.Record("GET", "/a", 200)
+	is.Equal(strings.Join(report.Summary().Operations[0].Unseen, ","), "default")
+	report.Record("GET", "/a", 500)
+	is.Equal(len(report.Summary().Operations[0].Unseen), 0)
+}
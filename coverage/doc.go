@@ -0,0 +1,3 @@
+// Package coverage reports which operations of an API were
+// exercised by silk requests, and with which status codes.
+package coverage
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/matryer/silk/parse"
+	"github.com/matryer/silk/runner"
+)
+
+// curlCommand prints the requests in silk documents as curl
+// commands:
+//
+//	silk curl [-url url] [-vars files] file.silk.md[:line]...
+//
+// With a line, only the request at that line is printed, otherwise
+// every request is printed as a shell script.
+func curlCommand(opts *options, args []string, stdout, stderr io.Writer) error {
+	flags := commandFlagSet("silk curl", stderr)
+	rootURL := flags.String("url", opts.url, "target url (default -silk.url)")
+	vars := flags.String("vars", opts.varsFiles, "comma separated JSON files of variables to load (default -silk.vars)")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return errors.New("usage: silk curl [-url url] [-vars files] file.silk.md[:line]...")
+	}
+	if *rootURL == "" {
+		return errors.New("-url (or -silk.url) is required")
+	}
+	// requests are not run, so there is nothing to report to
+	r := runner.New(nil, *rootURL)
+	r.Verbose = func(...interface{}) {}
+	r.FollowRedirects = opts.redirects
+	if *vars != "" {
+		for _, filename := range strings.Split(*vars, ",") {
+			if err := r.LoadVars(strings.TrimSpace(filename)); err != nil {
+				return err
+			}
+		}
+	}
+	var commands []string
+	// captured are the variables captured by requests, which
+	// are left as {placeholders}
+	var captured []string
+	script := false
+	for _, arg := range flags.Args() {
+		filename, line := splitLine(arg)
+		if line == 0 {
+			script = true
+		}
+		groups, err := parse.ParseFile(filename)
+		if err != nil {
+			return err
+		}
+		captured = append(captured, capturedVars(groups)...)
+		type request struct {
+			group *parse.Group
+			req   *parse.Request
+		}
+		var requests []request
+		for _, group := range groups {
+			for _, req := range group.Requests {
+				if line > 0 && req.Line <= line {
+					// the last request that begins before the line
+					requests = []request{}
+				} else if line > 0 {
+					continue
+				}
+				requests = append(requests, request{group: group, req: req})
+			}
+		}
+		if line > 0 && len(requests) == 0 {
+			return fmt.Errorf("%s: no request at line %d", filename, line)
+		}
+		for _, item := range requests {
+			cmd, err := r.Curl(item.group, item.req)
+			if err != nil {
+				return err
+			}
+			comments := []string{fmt.Sprintf("# %s:%d %s %s", filename, item.req.Line, item.req.Method, item.req.Path)}
+			for _, name := range placeholders(cmd, captured) {
+				comments = append(comments, "# {"+name+"} is captured at runtime")
+			}
+			commands = append(commands, strings.Join(comments, "\n")+"\n"+cmd)
+		}
+	}
+	if script {
+		fmt.Fprint(stdout, "#!/bin/sh\n\n")
+	}
+	fmt.Fprintln(stdout, strings.Join(commands, "\n\n"))
+	return nil
+}
+
+// capturedVars gets the names of the variables captured by the
+// requests in groups, like id in * Data.id: 1 // {id}.
+func capturedVars(groups []*parse.Group) []string {
+	var names []string
+	for _, group := range groups {
+		for _, req := range group.Requests {
+			for _, line := range req.ExpectedDetails {
+				if name := line.Capture(); len(name) > 0 {
+					names = append(names, name)
+				}
+			}
+		}
+	}
+	return names
+}
+
+// placeholders gets the names that are still {placeholders} in
+// the command, including escaped ones in the URL.
+func placeholders(cmd string, names []string) []string {
+	var found []string
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		if strings.Contains(cmd, "{"+name+"}") ||
+			strings.Contains(cmd, "%7B"+url.PathEscape(name)+"%7D") ||
+			strings.Contains(cmd, "%7B"+url.QueryEscape(name)+"%7D") {
+			found = append(found, name)
+		}
+	}
+	return found
+}
+
+// splitLine splits file.silk.md:12 into the filename and line,
+// which is zero if there isn't one.
+func splitLine(arg string) (string, int) {
+	i := strings.LastIndex(arg, ":")
+	if i == -1 {
+		return arg, 0
+	}
+	line, err := strconv.Atoi(arg[i+1:])
+	if err != nil || line < 1 {
+		return arg, 0
+	}
+	return arg[:i], line
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/matryer/silk/docs"
+	"github.com/matryer/silk/parse"
+	"github.com/matryer/silk/runner"
+)
+
+// docsCommand renders silk documents into an HTML site:
+//
+//	silk docs [-out dir] [-results file] [-title title] [-force] dir|files...
+//
+// With the results of a run (see -silk.results), each request
+// shows whether it passed and the response it received.
+func docsCommand(_ *options, args []string, stdout, stderr io.Writer) error {
+	flags := commandFlagSet("silk docs", stderr)
+	out := flags.String("out", "docs", "directory to write the site to")
+	resultsFile := flags.String("results", "", "JSON results of a run, written by -silk.results")
+	title := flags.String("title", "API", "title of the site")
+	force := flags.Bool("force", false, "overwrite existing pages")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return errors.New("usage: silk docs [-out dir] [-results file] [-title title] [-force] dir|files...")
+	}
+	files, err := documentFiles(flags.Args())
+	if err != nil {
+		return err
+	}
+	groups, err := parse.ParseFile(files...)
+	if err != nil {
+		return err
+	}
+	var results []*runner.Result
+	if *resultsFile != "" {
+		if results, err = runner.LoadResults(*resultsFile); err != nil {
+			return err
+		}
+	}
+	pages, err := docs.Build(*title, groups, results)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(*out, 0755); err != nil {
+		return err
+	}
+	for _, page := range pages {
+		path := filepath.Join(*out, page.Name)
+		if !*force {
+			if _, err := os.Stat(path); err == nil {
+				return fmt.Errorf("%s already exists (use -force to overwrite)", path)
+			}
+		}
+		if err := ioutil.WriteFile(path, page.HTML, 0644); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintln(stdout, "silk: wrote", len(pages), "page(s) to", *out)
+	return nil
+}
+
+// documentFiles gets the silk documents in args, which are
+// files or directories of .silk.md files.
+func documentFiles(args []string) ([]string, error) {
+	var files []string
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, arg)
+			continue
+		}
+		var found []string
+		err = filepath.Walk(arg, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() && strings.HasSuffix(path, ".silk.md") {
+				found = append(found, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(found) == 0 {
+			return nil, fmt.Errorf("%s: no .silk.md files", arg)
+		}
+		sort.Strings(found)
+		files = append(files, found...)
+	}
+	return files, nil
+}
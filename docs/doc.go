@@ -0,0 +1,3 @@
+// Package docs renders silk documents into a static HTML site,
+// optionally with the results of a run next to each request.
+package docs
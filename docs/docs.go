@@ -0,0 +1,283 @@
+package docs
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"html/template"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/matryer/silk/parse"
+	"github.com/matryer/silk/runner"
+)
+
+// Page is a page of the site.
+type Page struct {
+	// Name is the file name, like users.html.
+	Name string
+	HTML []byte
+}
+
+// Statuses of requests, when there are results.
+const (
+	StatusPassed = "passed"
+	StatusFailed = "failed"
+	StatusNotRun = "not run"
+)
+
+// Build renders groups, from one or more documents, into an
+// index.html page and a page for each document.
+// Results are optional; when given, each request is shown with
+// its status and the response it received.
+func Build(title string, groups []*parse.Group, results []*runner.Result) ([]*Page, error) {
+	var docs []*document
+	byFile := make(map[string]*document)
+	// index.html is taken by the index
+	names := map[string]bool{"index.html": true}
+	for _, group := range groups {
+		doc, ok := byFile[group.Filename]
+		if !ok {
+			doc = &document{
+				Filename: group.Filename,
+				Name:     pageName(group.Filename, names),
+				Title:    string(group.Title),
+			}
+			byFile[group.Filename] = doc
+			docs = append(docs, doc)
+		}
+		doc.Groups = append(doc.Groups, newGroup(group, len(doc.Groups)+1, results))
+	}
+	site := &site{Title: title, Docs: docs, HasResults: results != nil}
+	for _, doc := range docs {
+		for _, g := range doc.Groups {
+			for _, req := range g.Requests {
+				doc.count(req.Status)
+				site.Total.count(req.Status)
+			}
+		}
+	}
+	var pages []*Page
+	render := func(name string, doc *document) error {
+		var buf bytes.Buffer
+		if err := pageTemplate.Execute(&buf, &pageData{site: site, Doc: doc, Name: name}); err != nil {
+			return err
+		}
+		pages = append(pages, &Page{Name: name, HTML: buf.Bytes()})
+		return nil
+	}
+	if err := render("index.html", nil); err != nil {
+		return nil, err
+	}
+	for _, doc := range docs {
+		if err := render(doc.Name, doc); err != nil {
+			return nil, err
+		}
+	}
+	return pages, nil
+}
+
+type site struct {
+	Title      string
+	Docs       []*document
+	HasResults bool
+	Total      counts
+}
+
+type pageData struct {
+	*site
+	// Doc is the document of the page, or nil for the index.
+	Doc  *document
+	Name string
+}
+
+type counts struct {
+	Requests, Passed, Failed, NotRun int
+}
+
+func (c *counts) count(status string) {
+	c.Requests++
+	switch status {
+	case StatusPassed:
+		c.Passed++
+	case StatusFailed:
+		c.Failed++
+	case StatusNotRun:
+		c.NotRun++
+	}
+}
+
+type document struct {
+	counts
+	Filename string
+	Name     string
+	Title    string
+	Groups   []*group
+}
+
+type group struct {
+	Anchor      string
+	Title       string
+	Description template.HTML
+	Details     []template.HTML
+	Requests    []*request
+}
+
+type request struct {
+	Anchor      string
+	Line        int
+	Method      string
+	Path        string
+	Description template.HTML
+	Params      []template.HTML
+	Details     []template.HTML
+	Form        []template.HTML
+	Parts       []template.HTML
+	Body        template.HTML
+	BodyFile    string
+
+	ExpectedDescription template.HTML
+	ExpectedDetails     []template.HTML
+	ExpectedBody        template.HTML
+	ExpectedBodyType    string
+	ExpectedBodyFile    string
+
+	// Status is empty without results.
+	Status string
+	Result *runner.Result
+	// ResponseHeader lines, sorted by name.
+	ResponseHeader []string
+	ResponseBody   template.HTML
+}
+
+// newGroup makes the view of the nth group in a document.
+func newGroup(g *parse.Group, n int, results []*runner.Result) *group {
+	view := &group{
+		Anchor:      "group-" + strconv.Itoa(n),
+		Title:       string(g.Title),
+		Description: prose(g.Description),
+		Details:     details(g.Details),
+	}
+	for _, req := range g.Requests {
+		r := &request{
+			Anchor:              "L" + strconv.Itoa(req.Line),
+			Line:                req.Line,
+			Method:              string(req.Method),
+			Path:                string(req.Path),
+			Description:         prose(req.Description),
+			Params:              details(req.Params),
+			Details:             details(req.Details),
+			Form:                details(req.Form),
+			Parts:               details(req.Parts),
+			ExpectedDescription: prose(req.ExpectedDescription),
+			ExpectedDetails:     details(req.ExpectedDetails),
+			ExpectedBodyType:    req.ExpectedBodyType,
+		}
+		if len(req.Body) > 0 {
+			r.Body = highlight(req.BodyType, req.Body.String())
+		}
+		if req.BodyFile != nil {
+			r.BodyFile = req.BodyFile.Path
+		}
+		if len(req.ExpectedBody) > 0 {
+			r.ExpectedBody = highlight(req.ExpectedBodyType, req.ExpectedBody.String())
+		}
+		if req.ExpectedBodyFile != nil {
+			r.ExpectedBodyFile = req.ExpectedBodyFile.Path
+		}
+		if results != nil {
+			r.Status = StatusNotRun
+			if res := runner.FindResult(results, g.Filename, req.Line); res != nil {
+				r.result(res)
+			}
+		}
+		view.Requests = append(view.Requests, r)
+	}
+	return view
+}
+
+func (r *request) result(res *runner.Result) {
+	r.Result = res
+	r.Status = StatusFailed
+	if res.Passed {
+		r.Status = StatusPassed
+	}
+	if res.Response == nil {
+		return
+	}
+	for name, values := range res.Response.Header {
+		for _, v := range values {
+			r.ResponseHeader = append(r.ResponseHeader, name+": "+v)
+		}
+	}
+	sort.Strings(r.ResponseHeader)
+	bodyType := ""
+	if strings.Contains(res.Response.Header.Get("Content-Type"), "json") {
+		bodyType = "json"
+	}
+	r.ResponseBody = highlight(bodyType, res.Response.Body)
+}
+
+// details renders detail, param, form and part lines,
+// highlighting their values.
+func details(lines parse.Lines) []template.HTML {
+	var items []template.HTML
+	for _, line := range lines {
+		text := strings.TrimSpace(string(line.Bytes))
+		text = strings.TrimPrefix(text, "* ")
+		text = strings.Replace(text, "`", "", -1)
+		// highlight values, but not keys like Data[0].name
+		item := highlightJSON(text)
+		if i := strings.Index(text, ": "); i > -1 {
+			item = template.HTML(html.EscapeString(text[:i+2])) + highlightJSON(text[i+2:])
+		}
+		if len(line.Comment) > 0 {
+			item += template.HTML(span("comment", "//"+string(line.Comment)))
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+var codeRegexp = regexp.MustCompile("`([^`]+)`")
+
+// prose renders plain text as paragraphs, with `code`.
+func prose(lines parse.Lines) template.HTML {
+	var paragraphs []string
+	var current []string
+	flush := func() {
+		if len(current) > 0 {
+			text := html.EscapeString(strings.Join(current, " "))
+			text = codeRegexp.ReplaceAllString(text, "<code>$1</code>")
+			paragraphs = append(paragraphs, "<p>"+text+"</p>")
+			current = nil
+		}
+	}
+	for _, line := range lines {
+		text := strings.TrimSpace(string(line.Bytes))
+		if len(text) == 0 {
+			flush()
+			continue
+		}
+		current = append(current, text)
+	}
+	flush()
+	return template.HTML(strings.Join(paragraphs, "\n"))
+}
+
+// pageName gets a unique page name for a document, like
+// users.html for users.silk.md.
+func pageName(filename string, names map[string]bool) string {
+	base := filepath.Base(filename)
+	base = strings.TrimSuffix(base, ".md")
+	base = strings.TrimSuffix(base, ".silk")
+	name := base + ".html"
+	for i := 2; names[name]; i++ {
+		name = fmt.Sprintf("%s-%d.html", base, i)
+	}
+	names[name] = true
+	return name
+}
@@ -0,0 +1,72 @@
+package docs_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cheekybits/is"
+	"github.com/matryer/silk/docs"
+	"github.com/matryer/silk/parse"
+	"github.com/matryer/silk/runner"
+)
+
+func TestBuild(t *testing.T) {
+	is := is.New(t)
+	groups, err := parse.ParseFile("../testfiles/success/users.silk.md", "../testfiles/success/echo.success.silk.md")
+	is.NoErr(err)
+	pages, err := docs.Build("Users API", groups, nil)
+	is.NoErr(err)
+	is.Equal(len(pages), 3)
+	is.Equal(pages[0].Name, "index.html")
+	is.Equal(pages[1].Name, "users.html")
+	is.Equal(pages[2].Name, "echo.success.html")
+	index := string(pages[0].HTML)
+	is.True(strings.Contains(index, `<a href="users.html">Users</a>`))
+	is.True(strings.Contains(index, `<a href="echo.success.html">Echo server</a>`))
+	is.False(strings.Contains(index, "not run"))
+	users := string(pages[1].HTML)
+	// sidebar
+	is.True(strings.Contains(users, `<a href="users.html#L5"><span class="method">GET</span> /users</a>`))
+	// request
+	is.True(strings.Contains(users, `<div class="request" id="L28">`))
+	is.True(strings.Contains(users, "<p>Every request and response here matches <code>openapi.yaml</code>.</p>"))
+	is.True(strings.Contains(users, `<span class="key">&#34;name&#34;</span>: <span class="string">&#34;Silk&#34;</span>`))
+	is.True(strings.Contains(users, `Status: <span class="number">200</span>`))
+	is.False(strings.Contains(users, `class="badge`))
+}
+
+func TestBuildResults(t *testing.T) {
+	is := is.New(t)
+	groups, err := parse.ParseFile("../testfiles/success/users.silk.md")
+	is.NoErr(err)
+	results := []*runner.Result{{
+		// run from another directory
+		Filename: "testfiles/success/users.silk.md",
+		Line:     5,
+		Passed:   true,
+		Response: &runner.Response{Status: 200, Body: `[{"name":"Mat"}]`},
+	}, {
+		Filename: "../testfiles/success/users.silk.md",
+		Line:     14,
+		Failure: &runner.Failure{
+			Line:     18,
+			Message:  "Data.email doesn't match",
+			Key:      "Data.email",
+			Expected: `"david@example.com"`,
+			Actual:   `"dave@example.com"`,
+		},
+		Response: &runner.Response{Status: 200, Body: `{"email":"dave@example.com"}`},
+	}}
+	pages, err := docs.Build("Users API", groups, results)
+	is.NoErr(err)
+	index := string(pages[0].HTML)
+	is.True(strings.Contains(index, `<span class="badge passed">1 passed</span>`))
+	is.True(strings.Contains(index, `<span class="badge failed">1 failed</span>`))
+	is.True(strings.Contains(index, `<span class="badge not-run">3 not run</span>`))
+	users := string(pages[1].HTML)
+	is.True(strings.Contains(users, `<span class="dot passed" title="passed"></span><a href="users.html#L5">`))
+	is.True(strings.Contains(users, `<span class="dot failed" title="failed"></span><a href="users.html#L14">`))
+	is.True(strings.Contains(users, `<span class="dot not-run" title="not run"></span><a href="users.html#L21">`))
+	is.True(strings.Contains(users, "<strong>Line 18:</strong> Data.email doesn&#39;t match"))
+	is.True(strings.Contains(users, `<span class="key">&#34;email&#34;</span>:<span class="string">&#34;dave@example.com&#34;</span>`))
+}
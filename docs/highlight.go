@@ -0,0 +1,55 @@
+package docs
+
+import (
+	"html"
+	"html/template"
+	"regexp"
+	"strings"
+)
+
+// jsonTokenRegexp matches the JSON tokens that are highlighted;
+// strings followed by a colon are keys.
+var jsonTokenRegexp = regexp.MustCompile(`"(?:[^"\\]|\\.)*"(\s*:)?|-?\b\d+(?:\.\d+)?(?:[eE][+-]?\d+)?\b|\btrue\b|\bfalse\b|\bnull\b`)
+
+// highlightJSON escapes s, wrapping JSON tokens in spans
+// with classes for their kind.
+// Invalid JSON is highlighted as well as possible.
+func highlightJSON(s string) template.HTML {
+	var b strings.Builder
+	last := 0
+	for _, m := range jsonTokenRegexp.FindAllStringSubmatchIndex(s, -1) {
+		b.WriteString(html.EscapeString(s[last:m[0]]))
+		token := s[m[0]:m[1]]
+		switch {
+		case m[2] > -1:
+			// key, then the colon
+			b.WriteString(span("key", s[m[0]:m[2]]))
+			b.WriteString(html.EscapeString(s[m[2]:m[1]]))
+		case token[0] == '"':
+			b.WriteString(span("string", token))
+		case token == "true" || token == "false" || token == "null":
+			b.WriteString(span("literal", token))
+		default:
+			b.WriteString(span("number", token))
+		}
+		last = m[1]
+	}
+	b.WriteString(html.EscapeString(s[last:]))
+	return template.HTML(b.String())
+}
+
+func span(class, text string) string {
+	return `<span class="` + class + `">` + html.EscapeString(text) + `</span>`
+}
+
+// highlight highlights a body of the type (the text after the
+// code fence, like json).
+// Bodies that look like JSON are highlighted even without a type.
+func highlight(bodyType, body string) template.HTML {
+	trimmed := strings.TrimSpace(body)
+	looksJSON := strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")
+	if strings.HasPrefix(bodyType, "json") || (bodyType == "" && looksJSON) {
+		return highlightJSON(body)
+	}
+	return template.HTML(html.EscapeString(body))
+}
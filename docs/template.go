@@ -0,0 +1,171 @@
+package docs
+
+import (
+	"html/template"
+	"strings"
+)
+
+var pageTemplate = template.Must(template.New("page").Funcs(template.FuncMap{
+	"class": func(status string) string {
+		return strings.Replace(status, " ", "-", -1)
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{ if .Doc }}{{ .Doc.Title }} - {{ end }}{{ .Title }}</title>
+<style>
+body { margin: 0; font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; color: #24292e; display: flex; }
+nav { width: 280px; min-width: 280px; height: 100vh; overflow-y: auto; position: sticky; top: 0; background: #f6f8fa; border-right: 1px solid #e1e4e8; padding: 16px; box-sizing: border-box; font-size: 14px; }
+nav h1 { font-size: 18px; margin: 0 0 16px; }
+nav a { color: #24292e; text-decoration: none; }
+nav a:hover { text-decoration: underline; }
+nav ul { list-style: none; padding-left: 12px; margin: 4px 0; }
+nav > ul { padding-left: 0; }
+nav .current > a { font-weight: bold; }
+main { flex: 1; padding: 24px 40px; max-width: 960px; }
+.request { border: 1px solid #e1e4e8; border-radius: 6px; padding: 0 16px 16px; margin: 16px 0; }
+.method { font-family: monospace; font-weight: bold; }
+.path { font-family: monospace; }
+pre { background: #f6f8fa; padding: 12px; border-radius: 6px; overflow-x: auto; }
+code { font-family: SFMono-Regular, Consolas, monospace; font-size: 13px; }
+ul.details { font-family: monospace; font-size: 13px; }
+.key { color: #005cc5; }
+.string { color: #032f62; }
+.number, .literal { color: #d73a49; }
+.comment { color: #6a737d; margin-left: 8px; }
+.badge { display: inline-block; font-size: 12px; font-weight: normal; padding: 2px 8px; border-radius: 10px; color: #fff; vertical-align: middle; }
+.badge.passed { background: #28a745; }
+.badge.failed { background: #d73a49; }
+.badge.not-run { background: #959da5; }
+.dot { display: inline-block; width: 8px; height: 8px; border-radius: 4px; margin-right: 4px; }
+.dot.passed { background: #28a745; }
+.dot.failed { background: #d73a49; }
+.dot.not-run { background: #959da5; }
+.failure { background: #ffeef0; border: 1px solid #fdaeb7; border-radius: 6px; padding: 8px 12px; }
+table { border-collapse: collapse; }
+th, td { text-align: left; padding: 4px 12px; border-bottom: 1px solid #e1e4e8; }
+</style>
+</head>
+<body>
+<nav>
+<h1><a href="index.html">{{ .Title }}</a></h1>
+<ul>
+{{- range .Docs }}
+<li{{ if eq .Name $.Name }} class="current"{{ end }}><a href="{{ .Name }}">{{ .Title }}</a>
+<ul>
+{{- $doc := . }}
+{{- range .Groups }}
+<li><a href="{{ $doc.Name }}#{{ .Anchor }}">{{ .Title }}</a>
+<ul>
+{{- range .Requests }}
+<li>{{ if .Status }}<span class="dot {{ class .Status }}" title="{{ .Status }}"></span>{{ end }}<a href="{{ $doc.Name }}#{{ .Anchor }}"><span class="method">{{ .Method }}</span> {{ .Path }}</a></li>
+{{- end }}
+</ul>
+</li>
+{{- end }}
+</ul>
+</li>
+{{- end }}
+</ul>
+</nav>
+<main>
+{{- if .Doc }}
+{{- with .Doc }}
+<h1>{{ .Title }}</h1>
+<p><code>{{ .Filename }}</code></p>
+{{- range .Groups }}
+<section id="{{ .Anchor }}">
+<h2>{{ .Title }}</h2>
+{{ .Description }}
+{{- if .Details }}
+<ul class="details">{{ range .Details }}<li>{{ . }}</li>{{ end }}</ul>
+{{- end }}
+{{- range .Requests }}
+<div class="request" id="{{ .Anchor }}">
+<h3><span class="method">{{ .Method }}</span> <span class="path">{{ .Path }}</span>
+{{- if .Status }} <span class="badge {{ class .Status }}">{{ .Status }}</span>{{ end }}</h3>
+{{ .Description }}
+{{- if .Params }}
+<h4>Parameters</h4>
+<ul class="details">{{ range .Params }}<li>{{ . }}</li>{{ end }}</ul>
+{{- end }}
+{{- if .Details }}
+<h4>Request details</h4>
+<ul class="details">{{ range .Details }}<li>{{ . }}</li>{{ end }}</ul>
+{{- end }}
+{{- if .Form }}
+<h4>Form</h4>
+<ul class="details">{{ range .Form }}<li>{{ . }}</li>{{ end }}</ul>
+{{- end }}
+{{- if .Parts }}
+<h4>Parts</h4>
+<ul class="details">{{ range .Parts }}<li>{{ . }}</li>{{ end }}</ul>
+{{- end }}
+{{- if .BodyFile }}
+<h4>Request body</h4>
+<p>From <code>{{ .BodyFile }}</code></p>
+{{- else if .Body }}
+<h4>Request body</h4>
+<pre><code>{{ .Body }}</code></pre>
+{{- end }}
+{{ .ExpectedDescription }}
+{{- if .ExpectedDetails }}
+<h4>Expected response</h4>
+<ul class="details">{{ range .ExpectedDetails }}<li>{{ . }}</li>{{ end }}</ul>
+{{- end }}
+{{- if .ExpectedBodyFile }}
+<h4>Expected body{{ if .ExpectedBodyType }} ({{ .ExpectedBodyType }}){{ end }}</h4>
+<p>From <code>{{ .ExpectedBodyFile }}</code></p>
+{{- else if .ExpectedBody }}
+<h4>Expected body{{ if .ExpectedBodyType }} ({{ .ExpectedBodyType }}){{ end }}</h4>
+<pre><code>{{ .ExpectedBody }}</code></pre>
+{{- end }}
+{{- with .Result }}
+{{- with .Failure }}
+<div class="failure">
+<p><strong>Line {{ .Line }}:</strong> {{ .Message }}</p>
+{{- if .Key }}
+<p><code>{{ .Key }}</code> expected: <code>{{ .Expected }}</code> actual: <code>{{ .Actual }}</code></p>
+{{- end }}
+</div>
+{{- end }}
+{{- end }}
+{{- if .Result }}{{ if .Result.Response }}
+<details{{ if eq .Status "failed" }} open{{ end }}>
+<summary>Actual response: {{ .Result.Response.Status }} ({{ .Result.Duration }})</summary>
+{{- if .ResponseHeader }}
+<pre><code>{{ range .ResponseHeader }}{{ . }}
+{{ end }}</code></pre>
+{{- end }}
+{{- if .Result.Response.Body }}
+<pre><code>{{ .ResponseBody }}</code></pre>
+{{- end }}
+</details>
+{{- end }}{{ end }}
+</div>
+{{- end }}
+</section>
+{{- end }}
+{{- end }}
+{{- else }}
+<h1>{{ .Title }}</h1>
+{{- if .HasResults }}
+<p>
+<span class="badge passed">{{ .Total.Passed }} passed</span>
+<span class="badge failed">{{ .Total.Failed }} failed</span>
+<span class="badge not-run">{{ .Total.NotRun }} not run</span>
+</p>
+{{- end }}
+<table>
+<tr><th>Document</th><th>Requests</th>{{ if .HasResults }}<th>Passed</th><th>Failed</th><th>Not run</th>{{ end }}</tr>
+{{- range .Docs }}
+<tr><td><a href="{{ .Name }}">{{ .Title }}</a></td><td>{{ .Requests }}</td>{{ if $.HasResults }}<td>{{ .Passed }}</td><td>{{ .Failed }}</td><td>{{ .NotRun }}</td>{{ end }}</tr>
+{{- end }}
+</table>
+{{- end }}
+</main>
+</body>
+</html>
+`))
@@ -0,0 +1,3 @@
+// Package document writes silk Markdown documents, for
+// tools that generate or convert tests.
+package document
@@ -0,0 +1,177 @@
+package document
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/matryer/silk/parse"
+)
+
+// File is a document to be written to a file.
+type File struct {
+	Name   string
+	Groups []*Group
+}
+
+// Group is a group of requests, written as a # heading.
+type Group struct {
+	Title       string
+	Description string
+	Details     []Field
+	Requests    []*Request
+}
+
+// Request is a request and its assertions, written as a
+// ## METHOD /path heading.
+type Request struct {
+	Method      string
+	Path        string
+	Description string
+	Params      []Field
+	Details     []Field
+	// Form fields are sent URL encoded, and Parts as
+	// multipart form data.
+	Form     []Field
+	Parts    []Field
+	Body     string
+	BodyType string
+
+	ExpectedDetails  []Field
+	ExpectedBody     string
+	ExpectedBodyType string
+}
+
+// Field is a key and value, written as a list item like
+// * Key: value.
+type Field struct {
+	Key string
+	// Value is any JSON value, a string
+	// that looks like a /regex/, or a FileRef.
+	Value interface{}
+	// Comment is written after the value, for
+	// example to capture it in a {variable}.
+	Comment string
+}
+
+// FileRef is a Field value that refers to a file, written
+// as @path, like * Body: @fixtures/user.json.
+type FileRef string
+
+func (f Field) value() string {
+	if file, ok := f.Value.(FileRef); ok {
+		return "@" + string(file)
+	}
+	return parse.Value{Data: f.Value}.String()
+}
+
+func (f Field) comment() string {
+	if len(f.Comment) == 0 {
+		return ""
+	}
+	return " // " + f.Comment
+}
+
+// Write writes the groups to w.
+func Write(w io.Writer, groups ...*Group) error {
+	bw := bufio.NewWriter(w)
+	for i, group := range groups {
+		if i > 0 {
+			fmt.Fprintln(bw)
+		}
+		writeGroup(bw, group)
+	}
+	return bw.Flush()
+}
+
+func writeGroup(w io.Writer, group *Group) {
+	fmt.Fprintln(w, "# "+oneLine(group.Title))
+	writeProse(w, group.Description)
+	if len(group.Details) > 0 {
+		fmt.Fprintln(w)
+		for _, f := range group.Details {
+			fmt.Fprintf(w, "* %s: %s%s\n", f.Key, f.value(), f.comment())
+		}
+	}
+	for _, req := range group.Requests {
+		fmt.Fprintln(w)
+		writeRequest(w, req)
+	}
+}
+
+func writeRequest(w io.Writer, req *Request) {
+	fmt.Fprintf(w, "## %s %s\n", strings.ToUpper(req.Method), req.Path)
+	writeProse(w, req.Description)
+	if len(req.Params) > 0 || len(req.Details) > 0 || len(req.Form) > 0 || len(req.Parts) > 0 {
+		fmt.Fprintln(w)
+	}
+	for _, f := range req.Params {
+		fmt.Fprintf(w, "* ?%s=%s%s\n", f.Key, paramValue(f.Value), f.comment())
+	}
+	for _, f := range req.Form {
+		fmt.Fprintf(w, "* &%s=%s%s\n", f.Key, paramValue(f.Value), f.comment())
+	}
+	for _, f := range req.Parts {
+		fmt.Fprintf(w, "* +%s=%s%s\n", f.Key, paramValue(f.Value), f.comment())
+	}
+	for _, f := range req.Details {
+		fmt.Fprintf(w, "* %s: %s%s\n", f.Key, f.value(), f.comment())
+	}
+	writeCodeblock(w, req.BodyType, req.Body)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "---")
+	if len(req.ExpectedDetails) > 0 {
+		fmt.Fprintln(w)
+	}
+	for _, f := range req.ExpectedDetails {
+		fmt.Fprintf(w, "* %s: %s%s\n", f.Key, f.value(), f.comment())
+	}
+	writeCodeblock(w, req.ExpectedBodyType, req.ExpectedBody)
+}
+
+// paramValue gets the value of a parameter. Strings are
+// written as they are, unless they look like another type.
+func paramValue(v interface{}) string {
+	s, ok := v.(string)
+	if !ok {
+		return parse.Value{Data: v}.String()
+	}
+	if parse.ParseValue([]byte(s)).Data != s {
+		return parse.Value{Data: s}.String()
+	}
+	return s
+}
+
+func writeCodeblock(w io.Writer, bodyType, body string) {
+	if len(body) == 0 {
+		return
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "```"+bodyType)
+	fmt.Fprintln(w, strings.TrimRight(body, "\n"))
+	fmt.Fprintln(w, "```")
+}
+
+// specialLineRegexp matches lines that silk would not treat
+// as plain text.
+var specialLineRegexp = regexp.MustCompile("^(\\s*)(#|```|===|---|\\* )")
+
+// writeProse writes text, escaping any lines that silk
+// would otherwise parse.
+func writeProse(w io.Writer, text string) {
+	text = strings.TrimSpace(text)
+	if len(text) == 0 {
+		return
+	}
+	fmt.Fprintln(w)
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		fmt.Fprintln(w, specialLineRegexp.ReplaceAllString(line, `$1\$2`))
+	}
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,105 @@
+package document_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cheekybits/is"
+	"github.com/matryer/silk/document"
+	"github.com/matryer/silk/parse"
+)
+
+func TestWrite(t *testing.T) {
+	is := is.New(t)
+	var buf bytes.Buffer
+	err := document.Write(&buf, &document.Group{
+		Title:       "Users",
+		Description: "Manage users.\n\n* not a detail\n# not a group\n---",
+		Details: []document.Field{
+			{Key: "Root", Value: "http://localhost:8080"},
+		},
+		Requests: []*document.Request{{
+			Method:      "post",
+			Path:        "/users",
+			Description: "Create a user.",
+			Params: []document.Field{
+				{Key: "pretty", Value: true},
+				{Key: "name", Value: "silk"},
+				{Key: "code", Value: "123"},
+			},
+			Details: []document.Field{
+				{Key: "Content-Type", Value: "application/json"},
+			},
+			Body:     `{"name": "Silk"}`,
+			BodyType: "json",
+			ExpectedDetails: []document.Field{
+				{Key: "Status", Value: 201},
+				{Key: "Content-Type", Value: "/application/json/"},
+				{Key: "Data.id", Value: 1, Comment: "{id}"},
+			},
+			ExpectedBody:     `{"name": "Silk"}`,
+			ExpectedBodyType: "json",
+		}, {
+			Method: "GET",
+			Path:   "/users/{id}",
+			ExpectedDetails: []document.Field{
+				{Key: "Status", Value: 200},
+			},
+		}},
+	})
+	is.NoErr(err)
+	is.Equal(buf.String(), "# Users\n"+
+		"\n"+
+		"Manage users.\n"+
+		"\n"+
+		"\\* not a detail\n"+
+		"\\# not a group\n"+
+		"\\---\n"+
+		"\n"+
+		"* Root: \"http://localhost:8080\"\n"+
+		"\n"+
+		"## POST /users\n"+
+		"\n"+
+		"Create a user.\n"+
+		"\n"+
+		"* ?pretty=true\n"+
+		"* ?name=silk\n"+
+		"* ?code=\"123\"\n"+
+		"* Content-Type: \"application/json\"\n"+
+		"\n"+
+		"```json\n"+
+		"{\"name\": \"Silk\"}\n"+
+		"```\n"+
+		"\n"+
+		"---\n"+
+		"\n"+
+		"* Status: 201\n"+
+		"* Content-Type: /application/json/\n"+
+		"* Data.id: 1 // {id}\n"+
+		"\n"+
+		"```json\n"+
+		"{\"name\": \"Silk\"}\n"+
+		"```\n"+
+		"\n"+
+		"## GET /users/{id}\n"+
+		"\n"+
+		"---\n"+
+		"\n"+
+		"* Status: 200\n")
+
+	groups, err := parse.Parse("users.silk.md", &buf)
+	is.NoErr(err)
+	is.Equal(len(groups), 1)
+	is.Equal(len(groups[0].Details), 1)
+	is.Equal(len(groups[0].Requests), 2)
+	req := groups[0].Requests[0]
+	is.Equal(string(req.Method), "POST")
+	is.Equal(string(req.Path), "/users")
+	is.Equal(len(req.Params), 3)
+	is.Equal(req.Params[2].Detail().Value.Data, "123")
+	is.Equal(len(req.Details), 1)
+	is.Equal(req.Body.String(), `{"name": "Silk"}`)
+	is.Equal(len(req.ExpectedDetails), 3)
+	is.Equal(req.ExpectedDetails[2].Capture(), "id")
+	is.Equal(req.ExpectedBodyType, "json")
+}
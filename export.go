@@ -0,0 +1,59 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/matryer/silk/openapi"
+	"github.com/matryer/silk/parse"
+)
+
+// exportCommand exports silk documents to other formats:
+//
+//	silk export openapi [-out spec.yaml] [-title title] [-version version] files...
+func exportCommand(_ *options, args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("usage: silk export openapi [-out spec.yaml] [-title title] [-version version] files...")
+	}
+	switch args[0] {
+	case "openapi":
+		return exportOpenAPI(args[1:], stdout, stderr)
+	}
+	return fmt.Errorf("unknown export format %q", args[0])
+}
+
+func exportOpenAPI(args []string, stdout, stderr io.Writer) error {
+	flags := commandFlagSet("silk export openapi", stderr)
+	out := flags.String("out", "", "file to write the spec to (.json or .yaml), instead of stdout")
+	title := flags.String("title", "API", "title of the API")
+	apiVersion := flags.String("version", "1.0.0", "version of the API")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return errors.New("usage: silk export openapi [-out spec.yaml] [-title title] [-version version] files...")
+	}
+	groups, err := parse.ParseFile(flags.Args()...)
+	if err != nil {
+		return err
+	}
+	d := openapi.Export(openapi.Info{Title: *title, Version: *apiVersion}, groups...)
+	var b []byte
+	if strings.ToLower(filepath.Ext(*out)) == ".json" {
+		b, err = d.JSON()
+	} else {
+		b, err = d.YAML()
+	}
+	if err != nil {
+		return err
+	}
+	if len(*out) == 0 {
+		_, err := stdout.Write(b)
+		return err
+	}
+	return ioutil.WriteFile(*out, b, 0644)
+}
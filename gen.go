@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/matryer/silk/document"
+	"github.com/matryer/silk/gogen"
+	"github.com/matryer/silk/openapi"
+	"github.com/matryer/silk/parse"
+)
+
+// genCommand generates silk documents:
+//
+//	silk gen openapi [-out dir] [-force] spec.yaml
+//	silk gen go [-package name] [-out file_test.go] [-force] files...
+func genCommand(_ *options, args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("usage: silk gen openapi|go ...")
+	}
+	switch args[0] {
+	case "openapi":
+		return genOpenAPI(args[1:], stdout, stderr)
+	case "go":
+		return genGo(args[1:], stdout, stderr)
+	}
+	return fmt.Errorf("unknown generator %q", args[0])
+}
+
+func genOpenAPI(args []string, stdout, stderr io.Writer) error {
+	flags := commandFlagSet("silk gen openapi", stderr)
+	out := flags.String("out", ".", "directory to write the documents to")
+	force := flags.Bool("force", false, "overwrite existing documents")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New("usage: silk gen openapi [-out dir] [-force] spec.yaml")
+	}
+	spec, err := openapi.Load(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	return writeFiles(*out, *force, openapi.Generate(spec), stdout)
+}
+
+// genGo writes a Go test file for silk documents, to stdout
+// unless -out is given.
+func genGo(args []string, stdout, stderr io.Writer) error {
+	flags := commandFlagSet("silk gen go", stderr)
+	pkg := flags.String("package", "", "package of the test file (default from the directory of -out, or silk_test)")
+	out := flags.String("out", "", "file to write the tests to (default stdout)")
+	force := flags.Bool("force", false, "overwrite an existing file")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return errors.New("usage: silk gen go [-package name] [-out file_test.go] [-force] files...")
+	}
+	groups, err := parse.ParseFile(flags.Args()...)
+	if err != nil {
+		return err
+	}
+	if *pkg == "" {
+		*pkg = "silk_test"
+		if *out != "" {
+			if dir, err := filepath.Abs(filepath.Dir(*out)); err == nil {
+				*pkg = packageName(filepath.Base(dir)) + "_test"
+			}
+		}
+	}
+	var buf bytes.Buffer
+	if err := gogen.Generate(&buf, *pkg, groups...); err != nil {
+		return err
+	}
+	if *out == "" {
+		_, err := stdout.Write(buf.Bytes())
+		return err
+	}
+	if !*force {
+		if _, err := os.Stat(*out); err == nil {
+			return fmt.Errorf("%s already exists (use -force to overwrite)", *out)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(*out), 0755); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(*out, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, "silk: wrote", *out)
+	return nil
+}
+
+var nonPackageRegexp = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// packageName makes a package name from a directory name.
+func packageName(dir string) string {
+	name := nonPackageRegexp.ReplaceAllString(strings.ToLower(dir), "")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "silk" + name
+	}
+	return name
+}
+
+// writeFiles writes the documents to dir, refusing to overwrite
+// existing files unless force is true, and lists them on stdout.
+func writeFiles(dir string, force bool, files []*document.File, stdout io.Writer) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for _, file := range files {
+		path := filepath.Join(dir, file.Name)
+		if !force {
+			if _, err := os.Stat(path); err == nil {
+				return fmt.Errorf("%s already exists (use -force to overwrite)", path)
+			}
+		}
+		if err := writeFile(path, file.Groups...); err != nil {
+			return err
+		}
+		fmt.Fprintln(stdout, "silk: wrote", path)
+	}
+	return nil
+}
+
+func writeFile(path string, groups ...*document.Group) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := document.Write(f, groups...); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
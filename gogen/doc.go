@@ -0,0 +1,3 @@
+// Package gogen turns silk documents into Go tests that use
+// only the standard library.
+package gogen
@@ -0,0 +1,455 @@
+package gogen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/matryer/silk/parse"
+)
+
+// Generate writes a Go test file in package pkg, with a test for each
+// document that the groups were parsed from.
+// Each request becomes a subtest, assertions become comparisons and
+// captured values become local variables.
+// Features that have no Go equivalent here (like cookie jars, markup
+// queries, schemas and body files) are errors.
+func Generate(w io.Writer, pkg string, groups ...*parse.Group) error {
+	g := &generator{}
+	g.printf("// Code generated by silk gen go. DO NOT EDIT.\n\n")
+	g.printf("package %s\n\n", pkg)
+	g.printf("import (\n")
+	for _, path := range imports {
+		g.printf("\t%q\n", path)
+	}
+	g.printf(")\n")
+	var filenames []string
+	files := make(map[string][]*parse.Group)
+	for _, group := range groups {
+		if _, ok := files[group.Filename]; !ok {
+			filenames = append(filenames, group.Filename)
+		}
+		files[group.Filename] = append(files[group.Filename], group)
+	}
+	tests := make(map[string]bool)
+	for _, filename := range filenames {
+		if err := g.file(filename, files[filename], tests); err != nil {
+			return err
+		}
+	}
+	g.printf("%s", helpers)
+	src, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("generated invalid code: %s", err)
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+type generator struct {
+	buf bytes.Buffer
+	// filename is the base name of the current document.
+	filename string
+	// locals maps the variables of the current document to
+	// the Go variables that hold them.
+	locals map[string]string
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// errorf makes an error at a line of the current document.
+func (g *generator) errorf(line int, format string, args ...interface{}) error {
+	return fmt.Errorf("%s:%d: %s", g.filename, line, fmt.Sprintf(format, args...))
+}
+
+// file writes the test for the groups of one document.
+func (g *generator) file(filename string, groups []*parse.Group, tests map[string]bool) error {
+	g.filename = filepath.Base(filename)
+	base := "Test" + exportedName(strings.TrimSuffix(strings.TrimSuffix(g.filename, ".md"), ".silk"))
+	name := base
+	for i := 2; tests[name]; i++ {
+		name = base + "_" + strconv.Itoa(i)
+	}
+	tests[name] = true
+	g.locals = locals(groups)
+	g.printf("\n// %s runs the requests in %s.\n", name, g.filename)
+	g.printf("func %s(t *testing.T) {\n", name)
+	g.printf("baseURL, done := silkStart()\n")
+	g.printf("defer done()\n")
+	if len(g.locals) > 0 {
+		var names []string
+		for _, ident := range g.locals {
+			names = append(names, ident)
+		}
+		sort.Strings(names)
+		g.printf("var %s string\n", strings.Join(names, ", "))
+	}
+	for _, group := range groups {
+		g.printf("// %s\n", strings.TrimSpace(string(group.Title)))
+		for _, line := range group.Details {
+			detail := line.Detail()
+			if detail == nil {
+				continue
+			}
+			if strings.EqualFold(detail.Key, "CookieJar") {
+				return g.errorf(line.Number, "cookie jars are not supported")
+			}
+			matches := varKeyRegexp.FindStringSubmatch(detail.Key)
+			if matches == nil {
+				continue
+			}
+			if ident, ok := g.locals[matches[1]]; ok {
+				g.printf("%s = %s\n", ident, g.expr(fmt.Sprintf("%v", detail.Value.Data)))
+			}
+		}
+		for _, req := range group.Requests {
+			if err := g.request(req); err != nil {
+				return err
+			}
+		}
+	}
+	g.printf("}\n")
+	return nil
+}
+
+// request writes the subtest for a request.
+func (g *generator) request(req *parse.Request) error {
+	if req.BodyFile != nil {
+		return g.errorf(req.BodyFile.Line, "body files are not supported")
+	}
+	if len(req.Parts) > 0 {
+		return g.errorf(req.Parts.Number(), "form parts are not supported")
+	}
+	if req.ExpectedBodyFile != nil {
+		return g.errorf(req.ExpectedBodyFile.Line, "expected body files are not supported")
+	}
+	if strings.HasPrefix(req.ExpectedBodyType, "jsonschema") {
+		return g.errorf(req.ExpectedBody.Number(), "schemas are not supported")
+	}
+	var call bytes.Buffer
+	fmt.Fprintf(&call, "silkDo(t, silkRequest{\n")
+	fmt.Fprintf(&call, "Method: %s,\n", g.expr(string(req.Method)))
+	fmt.Fprintf(&call, "URL: baseURL + %s,\n", g.expr(string(req.Path)))
+	if len(req.Params) > 0 {
+		fmt.Fprintf(&call, "Params: [][2]string{\n")
+		for _, line := range req.Params {
+			detail := line.Detail()
+			fmt.Fprintf(&call, "{%q, %s},\n", detail.Key, g.expr(fmt.Sprintf("%v", detail.Value.Data)))
+		}
+		fmt.Fprintf(&call, "},\n")
+	}
+	var headers []string
+	for _, line := range req.Details {
+		detail := line.Detail()
+		switch {
+		case strings.EqualFold(detail.Key, "CookieJar"):
+			return g.errorf(line.Number, "cookie jars are not supported")
+		case strings.EqualFold(detail.Key, "FollowRedirects"):
+			follow, ok := detail.Value.Data.(bool)
+			if !ok {
+				return g.errorf(line.Number, "invalid FollowRedirects (expected true or false)")
+			}
+			if follow {
+				fmt.Fprintf(&call, "Follow: true,\n")
+			}
+			continue
+		}
+		headers = append(headers, fmt.Sprintf("{%q, %s},\n", detail.Key, g.expr(fmt.Sprintf("%v", detail.Value.Data))))
+	}
+	if len(headers) > 0 {
+		fmt.Fprintf(&call, "Header: [][2]string{\n%s},\n", strings.Join(headers, ""))
+	}
+	if len(req.Form) > 0 {
+		fmt.Fprintf(&call, "Form: [][2]string{\n")
+		for _, line := range req.Form {
+			detail := line.Detail()
+			fmt.Fprintf(&call, "{%s, %s},\n", g.expr(detail.Key), g.expr(fmt.Sprintf("%v", detail.Value.Data)))
+		}
+		fmt.Fprintf(&call, "},\n")
+	}
+	if len(req.Body) > 0 {
+		fmt.Fprintf(&call, "Body: %s,\n", g.expr(req.Body.String()))
+	}
+	fmt.Fprintf(&call, "})\n")
+
+	var asserts bytes.Buffer
+	usesRes, usesBody := false, false
+	if len(req.ExpectedBody) > 0 {
+		usesBody = true
+		line := req.ExpectedBody.Number()
+		exp := g.expr(req.ExpectedBody.String())
+		fmt.Fprintf(&asserts, "// %s:%d\n", g.filename, line)
+		switch {
+		case strings.HasPrefix(req.ExpectedBodyType, "json") && strings.Contains(req.ExpectedBodyType, "exact"):
+			fmt.Fprintf(&asserts, "if !reflect.DeepEqual(silkParse([]byte(%s)), silkParse(body)) {\n", exp)
+		case strings.HasPrefix(req.ExpectedBodyType, "json"):
+			fmt.Fprintf(&asserts, "if !silkSubset(silkParse([]byte(%s)), silkParse(body)) {\n", exp)
+		default:
+			fmt.Fprintf(&asserts, "if string(body) != %s {\n", exp)
+		}
+		fmt.Fprintf(&asserts, "t.Fatalf(%q, body)\n}\n", g.filename+":"+strconv.Itoa(line)+": body doesn't match, actual:\n%s")
+	}
+	hasData := false
+	for _, line := range req.ExpectedDetails {
+		detail := line.Detail()
+		key := detail.Key
+		var actual string
+		optional := false
+		switch {
+		case key == "Status":
+			usesRes = true
+			actual = "var got interface{} = float64(res.StatusCode)"
+		case key == "Body":
+			usesBody = true
+			actual = "var got interface{} = string(body)"
+		case key == "Data" || strings.HasPrefix(key, "Data.") || strings.HasPrefix(key, "Data["):
+			path, ok := dataPath(key[len("Data"):])
+			if !ok {
+				return g.errorf(line.Number, "%s is not supported (only simple paths like Data.items[0].name are)", key)
+			}
+			if !hasData {
+				hasData = true
+				usesBody = true
+				fmt.Fprintf(&asserts, "data := silkData(t, body)\n")
+			}
+			actual = "got, ok := silkLookup(data" + path + ")"
+			optional = true
+		case strings.ContainsAny(key, ".[(") || key == "Schema" || key == "Set-Cookie" || key == "URL" || key == "Redirects":
+			return g.errorf(line.Number, "%s is not supported", key)
+		default:
+			usesRes = true
+			actual = fmt.Sprintf("got, ok := silkHeader(res, %q)", key)
+		}
+		capture, hasCapture := g.locals[line.Capture()]
+		if status, ok := detail.Value.Data.(float64); ok && key == "Status" && !hasCapture {
+			fmt.Fprintf(&asserts, "// %s:%d\n", g.filename, line.Number)
+			fmt.Fprintf(&asserts, "if res.StatusCode != %s {\n", strconv.FormatFloat(status, 'g', -1, 64))
+			fmt.Fprintf(&asserts, "t.Fatalf(%q, res.StatusCode)\n}\n", fmt.Sprintf("%s:%d: Status expected: %s  actual: %%d", g.filename, line.Number, detail.Value))
+			continue
+		}
+		cond := g.condition(detail.Value.Data)
+		if strings.HasPrefix(actual, "got, ok") {
+			if detail.Value.Data == nil && optional {
+				cond = "ok && " + cond
+			} else {
+				cond = "!ok || " + cond
+			}
+		}
+		fmt.Fprintf(&asserts, "{\n// %s:%d\n%s\n", g.filename, line.Number, actual)
+		fmt.Fprintf(&asserts, "if %s {\n", cond)
+		message := fmt.Sprintf("%s:%d: %s expected: %s  actual: ", g.filename, line.Number, key, detail.Value)
+		fmt.Fprintf(&asserts, "t.Fatalf(%q, got)\n}\n", strings.Replace(message, "%", "%%", -1)+"%v")
+		if hasCapture {
+			fmt.Fprintf(&asserts, "%s = silkString(got)\n", capture)
+		}
+		fmt.Fprintf(&asserts, "}\n")
+	}
+
+	res, body := "_", "_"
+	if usesRes {
+		res = "res"
+	}
+	if usesBody {
+		body = "body"
+	}
+	g.printf("if !t.Run(%q, func(t *testing.T) {\n", string(req.Method)+" "+string(req.Path))
+	if usesRes || usesBody {
+		g.printf("%s, %s := ", res, body)
+	}
+	g.printf("%s%s", call.String(), asserts.String())
+	g.printf("}) {\nreturn\n}\n")
+	return nil
+}
+
+// condition gets the Go condition that is true when got does
+// not match the expected value.
+func (g *generator) condition(expected interface{}) string {
+	switch v := expected.(type) {
+	case nil:
+		return "got != nil"
+	case bool:
+		return fmt.Sprintf("got != %t", v)
+	case float64:
+		return fmt.Sprintf("got != float64(%s)", strconv.FormatFloat(v, 'g', -1, 64))
+	case string:
+		if len(v) > 1 && strings.HasPrefix(v, "/") && strings.HasSuffix(v, "/") {
+			return fmt.Sprintf("!silkMatch(%s, silkString(got))", g.expr(v[1:len(v)-1]))
+		}
+		return fmt.Sprintf("silkString(got) != %s", g.expr(v))
+	}
+	return fmt.Sprintf("!reflect.DeepEqual(got, silkJSON(t, %s))", quote(parse.Value{Data: expected}.String()))
+}
+
+// varRefRegexp matches references to variables, like {id}.
+var varRefRegexp = regexp.MustCompile(`{([^{}\s"':,]+)}`)
+
+// varKeyRegexp matches the keys of group details that set
+// variables, like {host}.
+var varKeyRegexp = regexp.MustCompile(`^{([^{}]+)}$`)
+
+// expr gets a Go string expression for s, with references to
+// variables replaced by the local variables that hold them, or
+// the environment variables of the same name.
+func (g *generator) expr(s string) string {
+	var parts []string
+	last := 0
+	for _, m := range varRefRegexp.FindAllStringSubmatchIndex(s, -1) {
+		if m[0] > last {
+			parts = append(parts, quote(s[last:m[0]]))
+		}
+		name := s[m[2]:m[3]]
+		if ident, ok := g.locals[name]; ok {
+			parts = append(parts, ident)
+		} else {
+			parts = append(parts, fmt.Sprintf("silkEnv(%q)", name))
+		}
+		last = m[1]
+	}
+	if last < len(s) || len(parts) == 0 {
+		parts = append(parts, quote(s[last:]))
+	}
+	return strings.Join(parts, " + ")
+}
+
+// quote quotes s as a Go string, using a raw string for
+// multiple lines and quotes.
+func quote(s string) string {
+	if strings.ContainsAny(s, "\n\"") && !strings.ContainsAny(s, "`\r") {
+		return "`" + s + "`"
+	}
+	return strconv.Quote(s)
+}
+
+var dataStepRegexp = regexp.MustCompile(`^(?:\.([^.\[\]]+)|\[(-?[0-9]+)\]|\['([^']*)'\])`)
+
+// dataPath turns the path after Data (like .items[0].name) into
+// the arguments for silkLookup, if it is a simple path.
+func dataPath(path string) (string, bool) {
+	var args string
+	for len(path) > 0 {
+		m := dataStepRegexp.FindStringSubmatch(path)
+		if m == nil || m[1] == "*" {
+			return "", false
+		}
+		switch {
+		case len(m[2]) > 0:
+			args += ", " + m[2]
+		case len(m[1]) > 0:
+			args += ", " + strconv.Quote(m[1])
+		default:
+			args += ", " + strconv.Quote(m[3])
+		}
+		path = path[len(m[0]):]
+	}
+	return args, true
+}
+
+// locals gets the Go variables for the variables that are
+// declared or captured in the groups, and used.
+func locals(groups []*parse.Group) map[string]string {
+	var set []string
+	var used []string
+	use := func(s string) {
+		for _, m := range varRefRegexp.FindAllStringSubmatch(s, -1) {
+			used = append(used, m[1])
+		}
+	}
+	useLines := func(lines parse.Lines) {
+		for _, line := range lines {
+			if detail := line.Detail(); detail != nil {
+				use(detail.Key)
+				use(fmt.Sprintf("%v", detail.Value.Data))
+			}
+		}
+	}
+	for _, group := range groups {
+		for _, line := range group.Details {
+			if detail := line.Detail(); detail != nil {
+				if m := varKeyRegexp.FindStringSubmatch(detail.Key); m != nil {
+					set = append(set, m[1])
+					use(fmt.Sprintf("%v", detail.Value.Data))
+				}
+			}
+		}
+		for _, req := range group.Requests {
+			use(string(req.Method))
+			use(string(req.Path))
+			use(req.Body.String())
+			use(req.ExpectedBody.String())
+			useLines(req.Params)
+			useLines(req.Details)
+			useLines(req.Form)
+			useLines(req.ExpectedDetails)
+			for _, line := range req.ExpectedDetails {
+				if capture := line.Capture(); len(capture) > 0 {
+					set = append(set, capture)
+				}
+			}
+		}
+	}
+	locals := make(map[string]string)
+	idents := make(map[string]bool)
+	for _, name := range set {
+		if _, ok := locals[name]; ok || !containsString(used, name) {
+			continue
+		}
+		ident := localName(name)
+		for i := 2; idents[ident]; i++ {
+			ident = localName(name) + strconv.Itoa(i)
+		}
+		idents[ident] = true
+		locals[name] = ident
+	}
+	return locals
+}
+
+// reserved are the names used by the generated code.
+var reserved = map[string]bool{
+	"t": true, "res": true, "body": true, "data": true, "got": true,
+	"ok": true, "baseURL": true, "done": true, "string": true,
+}
+
+// localName gets a Go variable name for a silk variable.
+func localName(name string) string {
+	ident := exportedName(name)
+	if len(ident) == 0 {
+		return "v"
+	}
+	ident = strings.ToLower(ident[:1]) + ident[1:]
+	if token.Lookup(ident).IsKeyword() || reserved[ident] || strings.HasPrefix(ident, "silk") || (ident[0] >= '0' && ident[0] <= '9') {
+		ident = "v" + strings.ToUpper(ident[:1]) + ident[1:]
+	}
+	return ident
+}
+
+var nonIdentRegexp = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// exportedName turns s (like users.success) into a Go name
+// (like UsersSuccess).
+func exportedName(s string) string {
+	var name string
+	for _, part := range nonIdentRegexp.Split(s, -1) {
+		if len(part) > 0 {
+			name += strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+	return name
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
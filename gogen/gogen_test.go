@@ -0,0 +1,115 @@
+package gogen_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cheekybits/is"
+	"github.com/matryer/silk/gogen"
+	"github.com/matryer/silk/parse"
+	"github.com/matryer/silk/testutil"
+)
+
+func TestGenerate(t *testing.T) {
+	is := is.New(t)
+	groups, err := parse.ParseFile("../testfiles/success/users.silk.md", "../testfiles/success/captured-vars.silk.md")
+	is.NoErr(err)
+	var buf bytes.Buffer
+	is.NoErr(gogen.Generate(&buf, "users_test", groups...))
+	src := buf.String()
+	is.True(strings.HasPrefix(src, "// Code generated by silk gen go. DO NOT EDIT."))
+	is.True(strings.Contains(src, "package users_test\n"))
+	is.True(strings.Contains(src, "func TestUsers(t *testing.T) {"))
+	is.True(strings.Contains(src, "func TestCapturedVars(t *testing.T) {"))
+	is.True(strings.Contains(src, `t.Run("GET /users/2", func(t *testing.T) {`))
+	is.True(strings.Contains(src, `got, ok := silkLookup(data, 0, "name")`))
+	// captured values are local variables
+	is.True(strings.Contains(src, "var status, value string"))
+	is.True(strings.Contains(src, `URL:    baseURL + "/echo/" + status,`))
+	is.True(strings.Contains(src, "status = silkString(got)"))
+	// environment variables are looked up when the test runs
+	is.True(strings.Contains(src, `silkEnv("$EnvStatus")`))
+}
+
+func TestGenerateUnsupported(t *testing.T) {
+	is := is.New(t)
+	groups, err := parse.ParseFile("../testfiles/success/markup.silk.md")
+	is.NoErr(err)
+	err = gogen.Generate(ioutil.Discard, "markup_test", groups...)
+	is.Err(err)
+	is.True(strings.Contains(err.Error(), "markup.silk.md:"))
+	is.True(strings.Contains(err.Error(), "is not supported"))
+}
+
+// TestGeneratedTests runs the generated tests against the servers
+// that the documents pass against.
+func TestGeneratedTests(t *testing.T) {
+	if testing.Short() {
+		t.Skip("compiles and runs generated tests")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go is not installed")
+	}
+	for k, v := range map[string]string{"$EnvStatus": "awesome", "$AppNameFromEnv": "Silk"} {
+		if prev, ok := os.LookupEnv(k); ok {
+			defer os.Setenv(k, prev)
+		} else {
+			defer os.Unsetenv(k)
+		}
+		os.Setenv(k, v)
+	}
+	for _, test := range []struct {
+		handler http.Handler
+		files   []string
+		// doc is used instead of files when it is set.
+		doc  string
+		fail bool
+	}{
+		{handler: testutil.UsersHandler(), files: []string{"users.silk.md"}},
+		{handler: http.NotFoundHandler(), files: []string{"users.silk.md"}, fail: true},
+		{handler: testutil.EchoDataHandler(), files: []string{"captured-vars.silk.md", "form.silk.md", "data.silk.md"}},
+		{handler: testutil.EchoHandler(), files: []string{"vars.silk.md", "echo.success.silk.md"}},
+		// header names in any case, and null values in subsets
+		{handler: testutil.EchoDataHandler(), doc: "# Echo\n## POST /echo\n* Content-Type: \"application/json\"\n```\n{\"nothing\":null}\n```\n===\n* server: \"EchoDataHandler\"\n```json\n{\"body\":{\"nothing\":null}}\n```\n"},
+	} {
+		is := is.New(t)
+		var filenames []string
+		for _, file := range test.files {
+			filenames = append(filenames, filepath.Join("../testfiles/success", file))
+		}
+		groups, err := parse.ParseFile(filenames...)
+		if len(test.doc) > 0 {
+			groups, err = parse.Parse("doc.silk.md", strings.NewReader(test.doc))
+		}
+		is.NoErr(err)
+		dir, err := ioutil.TempDir("", "silk-gogen")
+		is.NoErr(err)
+		defer os.RemoveAll(dir)
+		var buf bytes.Buffer
+		is.NoErr(gogen.Generate(&buf, "silk_test", groups...))
+		is.NoErr(ioutil.WriteFile(filepath.Join(dir, "silk_test.go"), buf.Bytes(), 0644))
+		is.NoErr(ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte("module silktest\n\ngo 1.13\n"), 0644))
+		s := httptest.NewServer(test.handler)
+		defer s.Close()
+		cmd := exec.Command(goBin, "test", "-count=1", ".", "-silk.url="+s.URL)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GOFLAGS=", "GOWORK=off", "GOPROXY=off")
+		out, err := cmd.CombinedOutput()
+		if test.fail {
+			is.Err(err)
+			is.True(strings.Contains(string(out), "users.silk.md:11: Status expected: 200  actual: 404"))
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %s\n%s\n%s", test.files, err, out, buf.String())
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package gogen
+
+// helpers are the functions shared by the generated tests.
+// They are written once into every generated file, so the file
+// needs nothing but the standard library.
+const helpers = `
+// silkURL is the URL of the server under test, used unless
+// silkServer is set.
+var silkURL = flag.String("silk.url", "http://localhost:8080", "target url")
+
+// silkServer starts the server under test, if set. Set it in
+// another file of the package, like:
+//
+//	func init() {
+//		silkServer = func() *httptest.Server {
+//			return httptest.NewServer(NewHandler())
+//		}
+//	}
+var silkServer func() *httptest.Server
+
+// silkStart gets the URL of the server under test, and a function
+// that stops it.
+func silkStart() (string, func()) {
+	if silkServer == nil {
+		return strings.TrimSuffix(*silkURL, "/"), func() {}
+	}
+	s := silkServer()
+	return s.URL, s.Close
+}
+
+// silkRequest describes a request.
+type silkRequest struct {
+	Method string
+	URL    string
+	Params [][2]string
+	Header [][2]string
+	Form   [][2]string
+	Body   string
+	// Follow is whether redirects are followed.
+	Follow bool
+}
+
+// silkDo makes the request, and gets the response and its body.
+func silkDo(t *testing.T, r silkRequest) (*http.Response, []byte) {
+	body := r.Body
+	contentType := ""
+	if r.Form != nil {
+		form := url.Values{}
+		for _, f := range r.Form {
+			form.Add(f[0], f[1])
+		}
+		body = form.Encode()
+		contentType = "application/x-www-form-urlencoded"
+	}
+	req, err := http.NewRequest(r.Method, r.URL, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("invalid request: %s", err)
+	}
+	if len(body) == 0 {
+		req.Body = nil
+	}
+	for _, h := range r.Header {
+		req.Header.Add(h[0], h[1])
+	}
+	if contentType != "" && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	q := req.URL.Query()
+	for _, p := range r.Params {
+		q.Add(p[0], p[1])
+	}
+	req.URL.RawQuery = q.Encode()
+	var res *http.Response
+	if r.Follow {
+		res, err = http.DefaultClient.Do(req)
+	} else {
+		res, err = http.DefaultTransport.RoundTrip(req)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	b, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("failed to read body: %s", err)
+	}
+	return res, b
+}
+
+// silkEnv gets the environment variable, or the {name} reference
+// if it is not set.
+func silkEnv(name string) string {
+	if v, ok := os.LookupEnv(name); ok {
+		return v
+	}
+	return "{" + name + "}"
+}
+
+// silkString formats a value like silk does.
+func silkString(v interface{}) string {
+	return fmt.Sprint(v)
+}
+
+// silkMatch gets whether s matches the regular expression.
+func silkMatch(pattern, s string) bool {
+	return regexp.MustCompile(pattern).MatchString(s)
+}
+
+// silkHeader gets the last value of the response header.
+func silkHeader(res *http.Response, key string) (interface{}, bool) {
+	values := res.Header[http.CanonicalHeaderKey(key)]
+	if len(values) == 0 {
+		return nil, false
+	}
+	return values[len(values)-1], true
+}
+
+// silkJSON decodes JSON.
+func silkJSON(t *testing.T, s string) interface{} {
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatalf("invalid JSON %q: %s", s, err)
+	}
+	return v
+}
+
+// silkData decodes the JSON body.
+func silkData(t *testing.T, body []byte) interface{} {
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		t.Fatalf("failed to parse body: %s", err)
+	}
+	return v
+}
+
+// silkParse decodes the JSON body, or gets nil if it
+// isn't JSON.
+func silkParse(body []byte) interface{} {
+	var v interface{}
+	json.Unmarshal(body, &v)
+	return v
+}
+
+// silkLookup gets the value in data at the path of keys and indexes.
+func silkLookup(data interface{}, path ...interface{}) (interface{}, bool) {
+	for _, step := range path {
+		switch s := step.(type) {
+		case string:
+			m, ok := data.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			if data, ok = m[s]; !ok {
+				return nil, false
+			}
+		case int:
+			list, ok := data.([]interface{})
+			if !ok {
+				return nil, false
+			}
+			if s < 0 {
+				s += len(list)
+			}
+			if s < 0 || s >= len(list) {
+				return nil, false
+			}
+			data = list[s]
+		}
+	}
+	return data, true
+}
+
+// silkSubset gets whether the expected JSON is a subset of
+// the actual JSON: objects may have extra keys, but other
+// values must be equal.
+func silkSubset(expected, actual interface{}) bool {
+	if e, ok := expected.(map[string]interface{}); ok {
+		a, ok := actual.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for k, v := range e {
+			av, ok := a[k]
+			if !ok || !silkSubset(v, av) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(expected, actual)
+}
+`
+
+// imports are the packages used by the helpers.
+var imports = []string{
+	"encoding/json",
+	"flag",
+	"fmt",
+	"io/ioutil",
+	"net/http",
+	"net/http/httptest",
+	"net/url",
+	"os",
+	"reflect",
+	"regexp",
+	"strings",
+	"testing",
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/matryer/silk/convert"
+	"github.com/matryer/silk/document"
+)
+
+// importCommand converts files from other tools into
+// silk documents:
+//
+//	silk import http [-out dir] [-force] files...
+//	silk import postman [-out dir] [-force] files...
+//	silk import har [-out dir] [-force] [-host host] [-path prefix] [-method methods] files...
+func importCommand(_ *options, args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("usage: silk import http|postman|har [-out dir] [-force] files...")
+	}
+	switch args[0] {
+	case "http":
+		return importFiles(importFlagSet("http", stderr), args[1:], stdout, convert.HTTP)
+	case "postman":
+		return importPostman(args[1:], stdout, stderr)
+	case "har":
+		return importHAR(args[1:], stdout, stderr)
+	}
+	return fmt.Errorf("unknown import format %q", args[0])
+}
+
+// importFiles parses the flags, converts each file with fn,
+// and writes the documents.
+func importFiles(flags *flag.FlagSet, args []string, stdout io.Writer, fn func(filename string, r io.Reader) (*document.File, error)) error {
+	out, force, filenames, err := importFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	var files []*document.File
+	for _, filename := range filenames {
+		file, err := func() (*document.File, error) {
+			f, err := os.Open(filename)
+			if err != nil {
+				return nil, err
+			}
+			defer f.Close()
+			return fn(filename, f)
+		}()
+		if err != nil {
+			return fmt.Errorf("%s: %s", filename, err)
+		}
+		files = append(files, file)
+	}
+	return writeFiles(out, force, files, stdout)
+}
+
+func importFlagSet(format string, stderr io.Writer) *flag.FlagSet {
+	return commandFlagSet("silk import "+format, stderr)
+}
+
+// importFlags parses the flags of silk import, along with any
+// flags already added to the set, and gets the files to import.
+func importFlags(flags *flag.FlagSet, args []string) (string, bool, []string, error) {
+	out := flags.String("out", ".", "directory to write the documents to")
+	force := flags.Bool("force", false, "overwrite existing documents")
+	if err := parseFlags(flags, args); err != nil {
+		return "", false, nil, err
+	}
+	if flags.NArg() == 0 {
+		return "", false, nil, fmt.Errorf("usage: %s [-out dir] [-force] files...", flags.Name())
+	}
+	return *out, *force, flags.Args(), nil
+}
+
+// importPostman converts Postman collections into documents.
+// Environments are converted into variable files (name.vars.json)
+// for use with -silk.vars.
+func importPostman(args []string, stdout, stderr io.Writer) error {
+	out, force, filenames, err := importFlags(importFlagSet("postman", stderr), args)
+	if err != nil {
+		return err
+	}
+	var files []*document.File
+	for _, filename := range filenames {
+		b, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return err
+		}
+		if !convert.IsPostmanEnvironment(b) {
+			file, err := convert.Postman(filename, bytes.NewReader(b))
+			if err != nil {
+				return fmt.Errorf("%s: %s", filename, err)
+			}
+			files = append(files, file)
+			continue
+		}
+		_, vars, err := convert.PostmanEnvironment(bytes.NewReader(b))
+		if err != nil {
+			return fmt.Errorf("%s: %s", filename, err)
+		}
+		base := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+		base = strings.TrimSuffix(base, ".postman_environment")
+		if err := writeVars(filepath.Join(out, base+".vars.json"), force, vars, stdout); err != nil {
+			return err
+		}
+	}
+	return writeFiles(out, force, files, stdout)
+}
+
+// importHAR converts HAR archives into documents, keeping only
+// the requests that match the filters.
+func importHAR(args []string, stdout, stderr io.Writer) error {
+	flags := importFlagSet("har", stderr)
+	host := flags.String("host", "", "only import requests to this host")
+	path := flags.String("path", "", "only import requests with paths beginning with this prefix")
+	methods := flags.String("method", "", "comma separated methods to import (default all)")
+	cookies := flags.Bool("cookies", false, "keep cookie values instead of replacing them with variables")
+	return importFiles(flags, args, stdout, func(filename string, r io.Reader) (*document.File, error) {
+		options := convert.HAROptions{Host: *host, PathPrefix: *path, KeepCookies: *cookies}
+		if *methods != "" {
+			options.Methods = strings.Split(*methods, ",")
+		}
+		return convert.HAR(filename, r, options)
+	})
+}
+
+// writeVars writes variables as a JSON file, as loaded
+// by -silk.vars.
+func writeVars(path string, force bool, vars map[string]interface{}, stdout io.Writer) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if !force {
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf("%s already exists (use -force to overwrite)", path)
+		}
+	}
+	b, err := json.MarshalIndent(vars, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path, append(b, '\n'), 0644); err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, "silk: wrote", path)
+	return nil
+}
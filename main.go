@@ -1,54 +1,187 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
-	"testing"
+	"io"
+	"os"
 
+	"github.com/matryer/silk/parse"
+	"github.com/matryer/silk/report"
 	"github.com/matryer/silk/runner"
 )
 
-var (
-	showVersion = flag.Bool("version", false, "show version and exit")
-	url         = flag.String("silk.url", "", "(required) target url")
-	help        = flag.Bool("help", false, "show help")
-	paths       []string
+// options are the flags of a run.
+type options struct {
+	version     bool
+	help        bool
+	url         string
+	redirects   bool
+	openapiFile string
+	coverFile   string
+	coverJSON   string
+	coverMin    float64
+	varsFiles   string
+	logCurl     bool
+	resultsFile string
+	markdownDir string
+	reportName  string
+	verbose     bool
+}
+
+// newFlagSet gets the flags of silk, set in opts when parsed.
+func newFlagSet(opts *options) *flag.FlagSet {
+	flags := flag.NewFlagSet("silk", flag.ContinueOnError)
+	flags.BoolVar(&opts.version, "version", false, "show version and exit")
+	flags.StringVar(&opts.url, "silk.url", "", "(required) target url")
+	flags.BoolVar(&opts.help, "help", false, "show help")
+	flags.BoolVar(&opts.redirects, "silk.redirects", false, "follow redirects")
+	flags.StringVar(&opts.openapiFile, "silk.openapi", "", "OpenAPI 3 spec to check requests and responses against")
+	flags.StringVar(&opts.coverFile, "silk.coverage", "", "OpenAPI 3 spec or route list to report endpoint coverage against")
+	flags.StringVar(&opts.coverJSON, "silk.coverage.json", "", "file to write the coverage report to as JSON")
+	flags.Float64Var(&opts.coverMin, "silk.coverage.min", 0, "minimum percentage of operations that must be covered")
+	flags.StringVar(&opts.varsFiles, "silk.vars", "", "comma separated JSON files of variables to load")
+	flags.BoolVar(&opts.logCurl, "silk.curl", false, "log an equivalent curl command when a request fails")
+	flags.StringVar(&opts.resultsFile, "silk.results", "", "file to write the results of every request to as JSON")
+	flags.StringVar(&opts.markdownDir, "silk.markdown", "", "directory to write an annotated Markdown report of each file to")
+	flags.StringVar(&opts.reportName, "silk.report", "", "format to report results in: tap, or github for GitHub Actions annotations")
+	flags.BoolVar(&opts.verbose, "silk.verbose", false, "log every request and response body")
+	return flags
+}
+
+// Exit codes of silk.
+const (
+	exitPassed = 0
+	// exitFailed is used when a request fails.
+	exitFailed = 1
+	// exitError is used for invalid flags, documents and
+	// configuration, when requests can't be run.
+	exitError = 2
 )
 
 func main() {
-	flag.Parse()
-	if *showVersion {
-		printversion()
-		return
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run runs silk with the command line args, writing to stdout
+// and stderr, and gets the exit code.
+func run(args []string, stdout, stderr io.Writer) int {
+	opts := &options{}
+	flags := newFlagSet(opts)
+	flags.SetOutput(stderr)
+	flags.Usage = func() { printhelp(stderr, flags) }
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitPassed
+		}
+		return exitError
 	}
-	if *help {
-		printhelp()
-		return
+	if opts.version {
+		printversion(stdout)
+		return exitPassed
 	}
-	if *url == "" {
-		fmt.Println("silk.url argument is required")
-		return
+	if opts.help {
+		printhelp(stdout, flags)
+		return exitPassed
 	}
-	paths = flag.Args()
-	testing.Main(func(pat, str string) (bool, error) { return true, nil },
-		[]testing.InternalTest{{Name: "silk", F: testFunc}},
-		nil,
-		nil)
+	if cmd, ok := commands[flags.Arg(0)]; ok {
+		switch err := cmd(opts, flags.Args()[1:], stdout, stderr); err {
+		case nil, flag.ErrHelp:
+			return exitPassed
+		case errFlags:
+			// already reported by the flag package
+			return exitError
+		default:
+			fmt.Fprintln(stderr, "silk:", err)
+			return exitError
+		}
+	}
+	return runFiles(opts, flags.Args(), stdout, stderr)
+}
+
+// commands are the subcommands of silk, like silk gen.
+// They are given the silk flags that come before them, and
+// write to stdout and stderr.
+var commands = map[string]func(opts *options, args []string, stdout, stderr io.Writer) error{
+	"gen":    genCommand,
+	"export": exportCommand,
+	"import": importCommand,
+	"curl":   curlCommand,
+	"docs":   docsCommand,
+}
+
+// errFlags is returned by commands for invalid flags, which
+// the flag package has already reported.
+var errFlags = errors.New("invalid flags")
+
+// commandFlagSet makes the flag set of a command, which reports
+// errors to stderr.
+func commandFlagSet(name string, stderr io.Writer) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	return flags
 }
 
-func testFunc(t *testing.T) {
-	r := runner.New(t, *url)
-	fmt.Println("silk: running", len(paths), "file(s)...")
-	r.RunGlob(paths, nil)
+// parseFlags parses the flags of a command. Errors are
+// flag.ErrHelp for -help, or errFlags.
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	err := flags.Parse(args)
+	if err != nil && err != flag.ErrHelp {
+		return errFlags
+	}
+	return err
+}
+
+// reportFormat is a format of -silk.report, written to stdout
+// after the run.
+type reportFormat struct {
+	write func(w io.Writer, groups []*parse.Group, results []*runner.Result) error
+	// quiet is whether the log is replaced by the report.
+	quiet bool
+}
+
+var reportFormats = map[string]reportFormat{
+	"tap":    {write: report.TAP, quiet: true},
+	"github": {write: githubReport},
+}
+
+// githubReport writes GitHub Actions annotations for failures,
+// and a summary table to $GITHUB_STEP_SUMMARY when it is set.
+func githubReport(w io.Writer, groups []*parse.Group, results []*runner.Result) error {
+	if err := report.GitHub(w, groups, results); err != nil {
+		return err
+	}
+	summaryFile := os.Getenv("GITHUB_STEP_SUMMARY")
+	if summaryFile == "" {
+		return nil
+	}
+	f, err := os.OpenFile(summaryFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if err := report.GitHubSummary(f, groups, results); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
 }
 
-func printhelp() {
-	printversion()
-	fmt.Println("usage: silk [file] [file2 [file3 [...]]")
-	fmt.Println("  e.g: silk ./test/*.silk.md")
-	flag.PrintDefaults()
+func printhelp(w io.Writer, flags *flag.FlagSet) {
+	printversion(w)
+	fmt.Fprintln(w, "usage: silk -silk.url url [flags] file [file2 [file3 [...]]]")
+	fmt.Fprintln(w, "  e.g: silk -silk.url http://localhost:8080 ./test/*.silk.md")
+	fmt.Fprintln(w, "       silk gen openapi [-out dir] [-force] spec.yaml")
+	fmt.Fprintln(w, "       silk gen go [-package name] [-out file_test.go] [-force] files...")
+	fmt.Fprintln(w, "       silk export openapi [-out spec.yaml] files...")
+	fmt.Fprintln(w, "       silk import http|postman|har [-out dir] [-force] files...")
+	fmt.Fprintln(w, "       silk curl [-url url] [-vars files] file.silk.md[:line]...")
+	fmt.Fprintln(w, "       silk docs [-out dir] [-results file] [-title title] [-force] dir|files...")
+	fmt.Fprintln(w, "exit status is 1 if a request fails, and 2 for invalid flags or documents")
+	flags.SetOutput(w)
+	flags.PrintDefaults()
 }
 
-func printversion() {
-	fmt.Println("silk", version)
+func printversion(w io.Writer) {
+	fmt.Fprintln(w, "silk", version)
 }
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cheekybits/is"
+	"github.com/matryer/silk/parse"
+	"github.com/matryer/silk/runner"
+	"github.com/matryer/silk/testutil"
+)
+
+const passingDoc = `# Hello
+
+## GET /hello
+
+===
+
+* Status: 200
+
+## GET /world
+
+===
+
+* Status: 200
+`
+
+// failingDoc fails at the second request, so the third
+// is skipped.
+const failingDoc = `# Hello
+
+## GET /hello
+
+===
+
+* Status: 200
+
+## GET /world
+
+===
+
+* Status: 404
+
+## GET /again
+
+===
+
+* Status: 200
+`
+
+// writeDocs writes documents to a temporary directory, and gets
+// their paths.
+func writeDocs(t *testing.T, docs map[string]string) (string, map[string]string) {
+	is := is.New(t)
+	dir, err := ioutil.TempDir("", "silk-main")
+	is.NoErr(err)
+	paths := make(map[string]string)
+	for name, doc := range docs {
+		path := filepath.Join(dir, name)
+		is.NoErr(ioutil.WriteFile(path, []byte(doc), 0644))
+		paths[name] = path
+	}
+	return dir, paths
+}
+
+func TestRunPassed(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(testutil.EchoHandler())
+	defer s.Close()
+	dir, paths := writeDocs(t, map[string]string{"hello.silk.md": passingDoc})
+	defer os.RemoveAll(dir)
+	resultsFile := filepath.Join(dir, "results.json")
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-silk.url", s.URL, "-silk.results", resultsFile, paths["hello.silk.md"]}, &stdout, &stderr)
+	is.Equal(code, exitPassed)
+	is.Equal(stderr.String(), "")
+	is.True(strings.Contains(stdout.String(), "silk: running 1 file(s)..."))
+	is.True(strings.Contains(stdout.String(), "silk: 2 passed, 0 failed, 0 skipped in "))
+	results, err := runner.LoadResults(resultsFile)
+	is.NoErr(err)
+	is.Equal(len(results), 2)
+	is.True(results[0].Passed)
+	is.True(results[1].Passed)
+}
+
+func TestRunFailed(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(testutil.EchoHandler())
+	defer s.Close()
+	dir, paths := writeDocs(t, map[string]string{
+		"failing.silk.md": failingDoc,
+		"hello.silk.md":   passingDoc,
+	})
+	defer os.RemoveAll(dir)
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-silk.url", s.URL, paths["failing.silk.md"], paths["hello.silk.md"]}, &stdout, &stderr)
+	is.Equal(code, exitFailed)
+	is.Equal(stderr.String(), "")
+	out := stdout.String()
+	is.True(strings.Contains(out, "Status expected"))
+	// FailNow stops the failing file, but not the next one
+	is.False(strings.Contains(out, "GET /again"))
+	is.True(strings.Contains(out, "silk: 3 passed, 1 failed, 1 skipped in "))
+}
+
+func TestRunMarkdown(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(testutil.EchoHandler())
+	defer s.Close()
+	dir, paths := writeDocs(t, map[string]string{"hello.silk.md": passingDoc})
+	defer os.RemoveAll(dir)
+	// a document with the same name in another directory
+	is.NoErr(os.Mkdir(filepath.Join(dir, "other"), 0755))
+	other := filepath.Join(dir, "other", "hello.silk.md")
+	is.NoErr(ioutil.WriteFile(other, []byte(failingDoc), 0644))
+	reports := filepath.Join(dir, "reports")
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-silk.url", s.URL, "-silk.markdown", reports, paths["hello.silk.md"], other}, &stdout, &stderr)
+	is.Equal(code, exitFailed)
+	first, err := ioutil.ReadFile(filepath.Join(reports, "hello.report.md"))
+	is.NoErr(err)
+	is.False(strings.Contains(string(first), "GET /again"))
+	second, err := ioutil.ReadFile(filepath.Join(reports, "hello-2.report.md"))
+	is.NoErr(err)
+	is.True(strings.Contains(string(second), "GET /again"))
+}
+
+func TestRunParseError(t *testing.T) {
+	is := is.New(t)
+	dir, paths := writeDocs(t, map[string]string{
+		"hello.silk.md": passingDoc,
+		"bad.silk.md":   "## GET /hello\n",
+	})
+	defer os.RemoveAll(dir)
+	var stdout, stderr bytes.Buffer
+	// no requests are made, so the url is never used
+	code := run([]string{"-silk.url", "http://localhost:0", paths["hello.silk.md"], paths["bad.silk.md"]}, &stdout, &stderr)
+	is.Equal(code, exitError)
+	is.Equal(stdout.String(), "")
+	is.Equal(stderr.String(), "silk: "+paths["bad.silk.md"]+":1: missing group header\n")
+}
+
+func TestRunBadFlag(t *testing.T) {
+	is := is.New(t)
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-silk.nope", "hello.silk.md"}, &stdout, &stderr)
+	is.Equal(code, exitError)
+	is.Equal(stdout.String(), "")
+	is.True(strings.Contains(stderr.String(), "flag provided but not defined: -silk.nope"))
+	is.True(strings.Contains(stderr.String(), "usage: silk -silk.url url"))
+}
+
+func TestRunErrors(t *testing.T) {
+	is := is.New(t)
+	for _, test := range []struct {
+		args   []string
+		stderr string
+	}{
+		{args: []string{"hello.silk.md"}, stderr: "silk: -silk.url is required\n"},
+		{args: []string{"-silk.url", "http://localhost:0"}, stderr: "silk: no files to run\n"},
+		{args: []string{"-silk.url", "http://localhost:0", "-silk.report", "xml", "hello.silk.md"}, stderr: "silk: unknown -silk.report format xml\n"},
+		{args: []string{"-silk.url", "http://localhost:0", "missing.silk.md"}, stderr: "silk: open missing.silk.md: no such file or directory\n"},
+	} {
+		var stdout, stderr bytes.Buffer
+		code := run(test.args, &stdout, &stderr)
+		is.Equal(code, exitError)
+		is.Equal(stdout.String(), "")
+		is.Equal(stderr.String(), test.stderr)
+	}
+}
+
+func TestRunTAP(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(testutil.EchoHandler())
+	defer s.Close()
+	dir, paths := writeDocs(t, map[string]string{"failing.silk.md": failingDoc})
+	defer os.RemoveAll(dir)
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-silk.url", s.URL, "-silk.report", "tap", paths["failing.silk.md"]}, &stdout, &stderr)
+	is.Equal(code, exitFailed)
+	// the report replaces the log, and the summary goes to stderr
+	is.True(strings.HasPrefix(stdout.String(), "TAP version 13\n"))
+	is.False(strings.Contains(stdout.String(), "silk:"))
+	is.True(strings.Contains(stderr.String(), "silk: 1 passed, 1 failed, 1 skipped in "))
+}
+
+func TestRunHelp(t *testing.T) {
+	is := is.New(t)
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-help"}, &stdout, &stderr)
+	is.Equal(code, exitPassed)
+	is.Equal(stderr.String(), "")
+	is.True(strings.HasPrefix(stdout.String(), "silk "+version+"\n"))
+	is.True(strings.Contains(stdout.String(), "-silk.url"))
+	is.False(strings.Contains(stdout.String(), "-test."))
+
+	stdout.Reset()
+	code = run([]string{"-version"}, &stdout, &stderr)
+	is.Equal(code, exitPassed)
+	is.Equal(stdout.String(), "silk "+version+"\n")
+}
+
+func TestCurlPlaceholders(t *testing.T) {
+	is := is.New(t)
+	groups, err := parse.ParseFile("testfiles/success/captured-vars.silk.md")
+	is.NoErr(err)
+	captured := capturedVars(groups)
+	is.Equal(captured, []string{"value", "status"})
+	is.Equal(placeholders(`curl -X POST 'http://localhost/echo/%7Bstatus%7D'`, captured), []string{"status"})
+	is.Equal(placeholders(`curl -H 'X-Status: {status}'`, captured), []string{"status"})
+	is.Equal(len(placeholders(`curl -H 'X-Status: awesome'`, captured)), 0)
+}
+
+func TestRunCommands(t *testing.T) {
+	is := is.New(t)
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"curl", "-badflag"}, &stdout, &stderr)
+	is.Equal(code, exitError)
+	is.Equal(stdout.String(), "")
+	is.True(strings.HasPrefix(stderr.String(), "flag provided but not defined: -badflag\n"))
+	is.False(strings.Contains(stderr.String(), "silk:"))
+
+	stdout.Reset()
+	stderr.Reset()
+	code = run([]string{"gen", "openapi", "-help"}, &stdout, &stderr)
+	is.Equal(code, exitPassed)
+	is.True(strings.Contains(stderr.String(), "-out"))
+
+	stdout.Reset()
+	stderr.Reset()
+	code = run([]string{"-silk.url", "http://localhost:8080", "curl", "testfiles/failure/curl.failure.silk.md:5"}, &stdout, &stderr)
+	is.Equal(code, exitPassed)
+	is.Equal(stderr.String(), "")
+	is.True(strings.HasPrefix(stdout.String(), "# testfiles/failure/curl.failure.silk.md:5 POST /users\ncurl -X POST 'http://localhost:8080/users?notify=true'"))
+
+	stdout.Reset()
+	stderr.Reset()
+	code = run([]string{"export", "openapi", "testfiles/success/users.silk.md"}, &stdout, &stderr)
+	is.Equal(code, exitPassed)
+	is.True(strings.HasPrefix(stdout.String(), "openapi: "))
+
+	stdout.Reset()
+	stderr.Reset()
+	code = run([]string{"docs"}, &stdout, &stderr)
+	is.Equal(code, exitError)
+	is.True(strings.HasPrefix(stderr.String(), "silk: usage: silk docs"))
+}
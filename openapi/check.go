@@ -0,0 +1,248 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Violation describes a way in which a request or response
+// does not match its operation.
+type Violation struct {
+	// Location is the invalid part of the request or response,
+	// like `query parameter "limit"` or "response body".
+	Location string
+	// Pointer is the JSON pointer to the invalid value
+	// in a body.
+	Pointer string
+	// Message describes the problem.
+	Message string
+}
+
+func (v Violation) String() string {
+	location := v.Location
+	if len(v.Pointer) > 0 {
+		location += " " + v.Pointer
+	}
+	return location + ": " + v.Message
+}
+
+// ignoredHeaders are header parameters that OpenAPI
+// says are ignored.
+var ignoredHeaders = map[string]bool{
+	"Accept":        true,
+	"Content-Type":  true,
+	"Authorization": true,
+}
+
+// CheckRequest checks the parameters and body of the request.
+// The request body has usually been read, so it is passed in.
+func (o *Operation) CheckRequest(r *http.Request, body []byte) []Violation {
+	var violations []Violation
+	path := o.pathValues(r.URL.Path)
+	query := r.URL.Query()
+	documented := make(map[string]bool)
+	for _, param := range o.params {
+		name, _ := param["name"].(string)
+		in, _ := param["in"].(string)
+		location := in + " parameter " + strconv.Quote(name)
+		var values []string
+		switch in {
+		case "path":
+			if v, ok := path[name]; ok {
+				values = []string{v}
+			}
+		case "query":
+			documented[name] = true
+			values = query[name]
+		case "header":
+			if ignoredHeaders[http.CanonicalHeaderKey(name)] {
+				continue
+			}
+			values = r.Header[http.CanonicalHeaderKey(name)]
+		case "cookie":
+			if c, err := r.Cookie(name); err == nil {
+				values = []string{c.Value}
+			}
+		default:
+			continue
+		}
+		if len(values) == 0 {
+			if in == "path" || param["required"] == true {
+				violations = append(violations, Violation{Location: location, Message: "missing required parameter"})
+			}
+			continue
+		}
+		violations = append(violations, o.spec.checkValues(location, param["schema"], values)...)
+	}
+	var undocumented []string
+	for name := range query {
+		if !documented[name] {
+			undocumented = append(undocumented, name)
+		}
+	}
+	sort.Strings(undocumented)
+	for _, name := range undocumented {
+		violations = append(violations, Violation{Location: "query parameter " + strconv.Quote(name), Message: "not documented"})
+	}
+	requestBody := o.spec.object(o.node["requestBody"])
+	switch {
+	case requestBody == nil:
+		if len(body) > 0 {
+			violations = append(violations, Violation{Location: "request body", Message: "not documented"})
+		}
+	case len(body) == 0:
+		if requestBody["required"] == true {
+			violations = append(violations, Violation{Location: "request body", Message: "missing required body"})
+		}
+	default:
+		violations = append(violations, o.spec.checkBody("request body", requestBody["content"], r.Header.Get("Content-Type"), body)...)
+	}
+	return violations
+}
+
+// CheckResponse checks the status, headers and body of a response.
+func (o *Operation) CheckResponse(status int, header http.Header, body []byte) []Violation {
+	response := o.Response(status)
+	if response == nil {
+		return []Violation{{Location: "response status", Message: fmt.Sprintf("%d not documented", status)}}
+	}
+	var violations []Violation
+	headers := o.spec.object(response["headers"])
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if http.CanonicalHeaderKey(name) == "Content-Type" {
+			continue
+		}
+		h := o.spec.object(headers[name])
+		location := "response header " + strconv.Quote(name)
+		values := header[http.CanonicalHeaderKey(name)]
+		if len(values) == 0 {
+			if h["required"] == true {
+				violations = append(violations, Violation{Location: location, Message: "missing required header"})
+			}
+			continue
+		}
+		violations = append(violations, o.spec.checkValues(location, h["schema"], values)...)
+	}
+	if len(body) > 0 {
+		if response["content"] == nil {
+			violations = append(violations, Violation{Location: "response body", Message: "not documented"})
+		} else {
+			violations = append(violations, o.spec.checkBody("response body", response["content"], header.Get("Content-Type"), body)...)
+		}
+	}
+	return violations
+}
+
+// Response gets the response object for the status, which may be
+// documented by its code, a range (like 2XX) or the default.
+func (o *Operation) Response(status int) map[string]interface{} {
+	responses := o.spec.object(o.node["responses"])
+	code := strconv.Itoa(status)
+	for _, key := range []string{code, code[:1] + "XX", code[:1] + "xx", "default"} {
+		if response := o.spec.object(responses[key]); response != nil {
+			return response
+		}
+	}
+	return nil
+}
+
+// Responses gets the documented response codes of the operation,
+// like 200, 4XX and default.
+func (o *Operation) Responses() []string {
+	responses := o.spec.object(o.node["responses"])
+	codes := make([]string, 0, len(responses))
+	for code := range responses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// checkValues checks the string values of a parameter or
+// header against its schema.
+func (s *Spec) checkValues(location string, schemaNode interface{}, values []string) []Violation {
+	if schemaNode == nil {
+		return nil
+	}
+	var violations []Violation
+	for _, v := range s.schema.Sub(schemaNode).Validate(s.coerce(schemaNode, values)) {
+		violations = append(violations, Violation{Location: location, Pointer: v.Pointer, Message: v.Message})
+	}
+	return violations
+}
+
+// checkBody checks that the media type of the body is in
+// the content object, and that JSON bodies match its schema.
+func (s *Spec) checkBody(location string, contentNode interface{}, contentType string, body []byte) []Violation {
+	content := s.object(contentNode)
+	if len(content) == 0 {
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(contentType))
+	}
+	var media map[string]interface{}
+	for _, key := range []string{mediaType, strings.Split(mediaType, "/")[0] + "/*", "*/*"} {
+		if media = s.object(content[key]); media != nil {
+			break
+		}
+	}
+	if media == nil {
+		return []Violation{{Location: location, Message: fmt.Sprintf("Content-Type %q not documented", contentType)}}
+	}
+	if media["schema"] == nil || !isJSON(mediaType) {
+		return nil
+	}
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return []Violation{{Location: location, Message: "invalid JSON: " + err.Error()}}
+	}
+	var violations []Violation
+	for _, v := range s.schema.Sub(media["schema"]).Validate(data) {
+		violations = append(violations, Violation{Location: location, Pointer: v.Pointer, Message: v.Message})
+	}
+	return violations
+}
+
+// coerce turns string values into the type described by
+// the schema, so they can be validated.
+func (s *Spec) coerce(schemaNode interface{}, values []string) interface{} {
+	sch := s.object(schemaNode)
+	if sch["type"] == "array" {
+		if len(values) == 1 {
+			values = strings.Split(values[0], ",")
+		}
+		items := make([]interface{}, len(values))
+		for i, v := range values {
+			items[i] = s.coerce(sch["items"], []string{v})
+		}
+		return items
+	}
+	v := values[0]
+	switch sch["type"] {
+	case "integer", "number":
+		if n, err := strconv.ParseFloat(v, 64); err == nil {
+			return n
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+	return v
+}
+
+func isJSON(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
@@ -0,0 +1,3 @@
+// Package openapi checks HTTP requests and responses against
+// the operations described by an OpenAPI 3 specification.
+package openapi
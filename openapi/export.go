@@ -0,0 +1,409 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/matryer/silk/parse"
+	"gopkg.in/yaml.v2"
+)
+
+// Info describes the API in an exported Document.
+type Info struct {
+	Title       string `json:"title" yaml:"title"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	Version     string `json:"version" yaml:"version"`
+}
+
+// Document is an OpenAPI 3 document made by Export.
+type Document struct {
+	OpenAPI string `json:"openapi" yaml:"openapi"`
+	Info    Info   `json:"info" yaml:"info"`
+	Tags    []*tag `json:"tags,omitempty" yaml:"tags,omitempty"`
+	// Paths maps paths to methods to operations.
+	Paths map[string]map[string]*operation `json:"paths" yaml:"paths"`
+}
+
+type tag struct {
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+type operation struct {
+	Tags        []string             `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Summary     string               `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Description string               `json:"description,omitempty" yaml:"description,omitempty"`
+	Parameters  []*parameter         `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	RequestBody *requestBody         `json:"requestBody,omitempty" yaml:"requestBody,omitempty"`
+	Responses   map[string]*response `json:"responses" yaml:"responses"`
+}
+
+type parameter struct {
+	Name     string      `json:"name" yaml:"name"`
+	In       string      `json:"in" yaml:"in"`
+	Required bool        `json:"required,omitempty" yaml:"required,omitempty"`
+	Schema   *typeSchema `json:"schema" yaml:"schema"`
+	Example  interface{} `json:"example,omitempty" yaml:"example,omitempty"`
+}
+
+type typeSchema struct {
+	Type string `json:"type" yaml:"type"`
+}
+
+type requestBody struct {
+	Required bool                  `json:"required,omitempty" yaml:"required,omitempty"`
+	Content  map[string]*mediaType `json:"content" yaml:"content"`
+}
+
+type mediaType struct {
+	Example interface{} `json:"example,omitempty" yaml:"example,omitempty"`
+}
+
+type response struct {
+	Description string                `json:"description" yaml:"description"`
+	Headers     map[string]*header    `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Content     map[string]*mediaType `json:"content,omitempty" yaml:"content,omitempty"`
+}
+
+type header struct {
+	Schema  *typeSchema `json:"schema" yaml:"schema"`
+	Example interface{} `json:"example,omitempty" yaml:"example,omitempty"`
+}
+
+// JSON gets the document as indented JSON.
+func (d *Document) JSON() ([]byte, error) {
+	return json.MarshalIndent(d, "", "  ")
+}
+
+// YAML gets the document as YAML.
+func (d *Document) YAML() ([]byte, error) {
+	return yaml.Marshal(d)
+}
+
+// Export builds an OpenAPI 3 document describing the requests
+// in the groups.
+// Each group becomes a tag, each method and path an operation
+// (with {variables} in the path as parameters), and bodies become
+// examples. Requests for the same operation are merged.
+func Export(info Info, groups ...*parse.Group) *Document {
+	d := &Document{
+		OpenAPI: "3.0.3",
+		Info:    info,
+		Paths:   make(map[string]map[string]*operation),
+	}
+	for _, group := range groups {
+		title := string(group.Title)
+		found := false
+		for _, t := range d.Tags {
+			if t.Name == title {
+				found = true
+				break
+			}
+		}
+		if !found {
+			d.Tags = append(d.Tags, &tag{Name: title, Description: prose(group.Description)})
+		}
+		for _, req := range group.Requests {
+			d.addRequest(group, req)
+		}
+	}
+	return d
+}
+
+var pathVarRegexp = regexp.MustCompile(`{([^}]+)}`)
+
+func (d *Document) addRequest(group *parse.Group, req *parse.Request) {
+	path := string(req.Path)
+	var query url.Values
+	if i := strings.Index(path, "?"); i > -1 {
+		query, _ = url.ParseQuery(path[i+1:])
+		path = path[:i]
+	}
+	method := strings.ToLower(string(req.Method))
+	methods, ok := d.Paths[path]
+	if !ok {
+		methods = make(map[string]*operation)
+		d.Paths[path] = methods
+	}
+	op, ok := methods[method]
+	if !ok {
+		op = &operation{Responses: make(map[string]*response)}
+		methods[method] = op
+	}
+	title := string(group.Title)
+	if !contains(op.Tags, title) {
+		op.Tags = append(op.Tags, title)
+	}
+	if len(op.Description) == 0 {
+		op.Description = prose(req.Description)
+		op.Summary = firstLine(op.Description)
+	}
+	for _, match := range pathVarRegexp.FindAllStringSubmatch(path, -1) {
+		op.addParameter(&parameter{Name: match[1], In: "path", Required: true, Schema: &typeSchema{Type: "string"}})
+	}
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		op.addParameter(newParameter(name, "query", query.Get(name)))
+	}
+	var contentType string
+	for _, line := range req.Params {
+		detail := line.Detail()
+		op.addParameter(newParameter(detail.Key, "query", detail.Value.Data))
+	}
+	for _, line := range req.Details {
+		detail := line.Detail()
+		switch http.CanonicalHeaderKey(detail.Key) {
+		case "Content-Type":
+			contentType = fmt.Sprint(detail.Value.Data)
+		case "Accept", "Authorization":
+			// not described by parameters
+		default:
+			if !controlKeys[detail.Key] {
+				op.addParameter(newParameter(detail.Key, "header", detail.Value.Data))
+			}
+		}
+	}
+	if mt, example, ok := requestExample(req, contentType); ok {
+		if op.RequestBody == nil {
+			op.RequestBody = &requestBody{Required: true, Content: make(map[string]*mediaType)}
+		}
+		if _, ok := op.RequestBody.Content[mt]; !ok {
+			op.RequestBody.Content[mt] = &mediaType{Example: example}
+		}
+	}
+	d.addResponse(op, req)
+}
+
+func (d *Document) addResponse(op *operation, req *parse.Request) {
+	code := "default"
+	var contentType string
+	headers := make(map[string]*header)
+	for _, line := range req.ExpectedDetails {
+		detail := line.Detail()
+		switch {
+		case detail.Key == "Status":
+			if status, ok := detail.Value.Data.(float64); ok {
+				code = fmt.Sprint(status)
+			}
+		case http.CanonicalHeaderKey(detail.Key) == "Content-Type":
+			contentType = mediaTypeOf(detail.Value)
+		case headerKeyRegexp.MatchString(detail.Key) && !nonHeaderKeys[detail.Key]:
+			h := &header{Schema: &typeSchema{Type: "string"}}
+			if detail.Value.Type() == "string" && !strings.Contains(detail.Value.Data.(string), "{") {
+				h.Example = detail.Value.Data
+			}
+			headers[detail.Key] = h
+		}
+	}
+	res, ok := op.Responses[code]
+	if !ok {
+		res = &response{Description: prose(req.ExpectedDescription)}
+		if len(res.Description) == 0 {
+			res.Description = responseDescription(code)
+		}
+		op.Responses[code] = res
+	}
+	for name, h := range headers {
+		if res.Headers == nil {
+			res.Headers = make(map[string]*header)
+		}
+		if _, ok := res.Headers[name]; !ok {
+			res.Headers[name] = h
+		}
+	}
+	var body []byte
+	switch {
+	case req.ExpectedBodyFile != nil:
+		body, _ = ioutil.ReadFile(req.ExpectedBodyFile.Path)
+	case len(req.ExpectedBody) > 0:
+		body = req.ExpectedBody.Bytes()
+	default:
+		return
+	}
+	if len(contentType) == 0 {
+		contentType = bodyMediaType(req.ExpectedBodyType)
+	}
+	if res.Content == nil {
+		res.Content = make(map[string]*mediaType)
+	}
+	if _, ok := res.Content[contentType]; !ok {
+		res.Content[contentType] = &mediaType{Example: example(contentType, body)}
+	}
+}
+
+func (op *operation) addParameter(p *parameter) {
+	for _, existing := range op.Parameters {
+		if existing.Name == p.Name && existing.In == p.In {
+			return
+		}
+	}
+	op.Parameters = append(op.Parameters, p)
+}
+
+// newParameter makes a parameter, with the value as its example
+// unless it refers to a {variable}.
+func newParameter(name, in string, value interface{}) *parameter {
+	p := &parameter{Name: name, In: in, Schema: &typeSchema{Type: "string"}}
+	switch v := value.(type) {
+	case float64:
+		p.Schema.Type = "number"
+		if v == float64(int64(v)) {
+			p.Schema.Type = "integer"
+		}
+	case bool:
+		p.Schema.Type = "boolean"
+	case string:
+		if strings.Contains(v, "{") {
+			return p
+		}
+	}
+	p.Example = value
+	return p
+}
+
+// requestExample gets the media type and example of the
+// request body, if there is one.
+func requestExample(req *parse.Request, contentType string) (string, interface{}, bool) {
+	switch {
+	case len(req.Form) > 0:
+		return "application/x-www-form-urlencoded", fieldsExample(req.Form), true
+	case len(req.Parts) > 0:
+		return "multipart/form-data", fieldsExample(req.Parts), true
+	}
+	var body []byte
+	switch {
+	case req.BodyFile != nil:
+		body, _ = ioutil.ReadFile(req.BodyFile.Path)
+	case len(req.Body) > 0:
+		body = req.Body.Bytes()
+	default:
+		return "", nil, false
+	}
+	if len(contentType) == 0 {
+		contentType = bodyMediaType(req.BodyType)
+	}
+	return contentType, example(contentType, body), true
+}
+
+func fieldsExample(lines parse.Lines) map[string]interface{} {
+	fields := make(map[string]interface{})
+	for _, line := range lines {
+		detail := line.Detail()
+		fields[detail.Key] = detail.Value.Data
+	}
+	return fields
+}
+
+// example gets a body as decoded JSON if it is JSON,
+// or as a string.
+func example(contentType string, body []byte) interface{} {
+	if !isJSON(contentType) {
+		if !utf8Text(body) {
+			return nil
+		}
+		return string(body)
+	}
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return string(body)
+	}
+	return v
+}
+
+func utf8Text(b []byte) bool {
+	return strings.HasPrefix(http.DetectContentType(b), "text/")
+}
+
+// bodyMediaType guesses the media type of a body from its
+// code block type.
+func bodyMediaType(bodyType string) string {
+	if strings.HasPrefix(bodyType, "json") {
+		return "application/json"
+	}
+	switch bodyType {
+	case "xml":
+		return "application/xml"
+	case "yaml":
+		return "application/x-yaml"
+	case "html":
+		return "text/html"
+	}
+	return "text/plain"
+}
+
+// mediaTypeOf gets the media type from a Content-Type assertion,
+// which may be a regex like /application/json/.
+func mediaTypeOf(v *parse.Value) string {
+	s := fmt.Sprint(v.Data)
+	if v.Type() == "regex" {
+		s = strings.Replace(s[1:len(s)-1], `\`, "", -1)
+		s = strings.Trim(s, "^$")
+	}
+	if i := strings.Index(s, ";"); i > -1 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s)
+}
+
+// controlKeys are request details that change how silk makes
+// the request, rather than headers.
+var controlKeys = map[string]bool{
+	"CookieJar":       true,
+	"FollowRedirects": true,
+}
+
+var headerKeyRegexp = regexp.MustCompile(`^[A-Za-z0-9-]+$`)
+
+// nonHeaderKeys are assertion keys that are not headers.
+var nonHeaderKeys = map[string]bool{
+	"Status":         true,
+	"Body":           true,
+	"Data":           true,
+	"Schema":         true,
+	"Set-Cookie":     true,
+	"URL":            true,
+	"Redirects":      true,
+	"Content-Length": true,
+}
+
+func responseDescription(code string) string {
+	var status int
+	if _, err := fmt.Sscan(code, &status); err == nil {
+		if text := http.StatusText(status); len(text) > 0 {
+			return text
+		}
+	}
+	return "Response"
+}
+
+// prose gets the text of plain lines, with surrounding
+// blank lines removed.
+func prose(lines parse.Lines) string {
+	return strings.TrimSpace(lines.String())
+}
+
+func firstLine(s string) string {
+	if i := strings.Index(s, "\n"); i > -1 {
+		return s[:i]
+	}
+	return s
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,286 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/matryer/silk/document"
+)
+
+// Generate makes silk documents for the operations in the spec,
+// one for each tag (or each path, for operations without tags).
+// Requests use the examples in the spec, and assert the first
+// successful status and its example response.
+// Tags with the same slug, like Users and users, get their own
+// documents, like users.silk.md and users-2.silk.md.
+func Generate(s *Spec) []*document.File {
+	var files []*document.File
+	byTitle := make(map[string]*document.File)
+	names := make(map[string]bool)
+	for _, op := range s.Operations {
+		title := op.Path
+		if len(op.Tags) > 0 {
+			title = op.Tags[0]
+		}
+		file, ok := byTitle[title]
+		if !ok {
+			group := &document.Group{Title: title}
+			if len(op.Tags) > 0 {
+				group.Description = s.tagDescription(title)
+			}
+			file = &document.File{Name: fileName(title, names), Groups: []*document.Group{group}}
+			byTitle[title] = file
+			files = append(files, file)
+		}
+		group := file.Groups[0]
+		group.Requests = append(group.Requests, s.generateRequest(op))
+	}
+	return files
+}
+
+func (s *Spec) tagDescription(name string) string {
+	tags, _ := s.doc["tags"].([]interface{})
+	for _, t := range tags {
+		tag := s.object(t)
+		if tag["name"] == name {
+			description, _ := tag["description"].(string)
+			return description
+		}
+	}
+	return ""
+}
+
+func (s *Spec) generateRequest(op *Operation) *document.Request {
+	req := &document.Request{
+		Method:      op.Method,
+		Path:        op.Path,
+		Description: strings.TrimSpace(op.Summary + "\n\n" + op.Description),
+	}
+	for _, param := range op.params {
+		name, _ := param["name"].(string)
+		example, ok := s.paramExample(param)
+		switch param["in"] {
+		case "path":
+			if ok {
+				req.Path = strings.Replace(req.Path, "{"+name+"}", url.PathEscape(fmt.Sprint(example)), -1)
+			}
+		case "query":
+			if !ok && param["required"] != true {
+				continue
+			}
+			if !ok {
+				example = "{" + name + "}"
+			}
+			req.Params = append(req.Params, document.Field{Key: name, Value: example})
+		case "header":
+			if !ok && param["required"] != true {
+				continue
+			}
+			if !ok {
+				example = "{" + name + "}"
+			}
+			req.Details = append(req.Details, document.Field{Key: name, Value: fmt.Sprint(example)})
+		}
+	}
+	if requestBody := s.object(op.node["requestBody"]); requestBody != nil {
+		if mediaType, media := s.preferredMedia(requestBody["content"]); media != nil {
+			req.Details = append(req.Details, document.Field{Key: "Content-Type", Value: mediaType})
+			if example, ok := s.mediaExample(media); ok {
+				req.Body, req.BodyType = formatExample(mediaType, example)
+			}
+		}
+	}
+	status, response := s.successResponse(op)
+	if response == nil {
+		return req
+	}
+	req.ExpectedDetails = append(req.ExpectedDetails, document.Field{Key: "Status", Value: status})
+	if mediaType, media := s.preferredMedia(response["content"]); media != nil {
+		req.ExpectedDetails = append(req.ExpectedDetails, document.Field{Key: "Content-Type", Value: "/" + regexp.QuoteMeta(mediaType) + "/"})
+		if example, ok := s.mediaExample(media); ok {
+			req.ExpectedBody, req.ExpectedBodyType = formatExample(mediaType, example)
+		}
+	}
+	return req
+}
+
+// successResponse gets the first 2xx response (or the first
+// response) of the operation, and its status.
+func (s *Spec) successResponse(op *Operation) (int, map[string]interface{}) {
+	responses := s.object(op.node["responses"])
+	var codes []string
+	for code := range responses {
+		if _, err := strconv.Atoi(code); err == nil {
+			codes = append(codes, code)
+		}
+	}
+	if len(codes) == 0 {
+		return 0, nil
+	}
+	sort.Strings(codes)
+	code := codes[0]
+	for _, c := range codes {
+		if strings.HasPrefix(c, "2") {
+			code = c
+			break
+		}
+	}
+	status, _ := strconv.Atoi(code)
+	return status, s.object(responses[code])
+}
+
+// preferredMedia gets the JSON media type from a content object,
+// or the first media type.
+func (s *Spec) preferredMedia(contentNode interface{}) (string, map[string]interface{}) {
+	content := s.object(contentNode)
+	if len(content) == 0 {
+		return "", nil
+	}
+	types := make([]string, 0, len(content))
+	for t := range content {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	mediaType := types[0]
+	for _, t := range types {
+		if isJSON(t) {
+			mediaType = t
+			break
+		}
+	}
+	return mediaType, s.object(content[mediaType])
+}
+
+func (s *Spec) paramExample(param map[string]interface{}) (interface{}, bool) {
+	if example, ok := s.example(param); ok {
+		return example, true
+	}
+	sch := s.object(param["schema"])
+	if sch == nil {
+		return nil, false
+	}
+	if example, ok := s.example(sch); ok {
+		return example, true
+	}
+	if def, ok := sch["default"]; ok {
+		return def, true
+	}
+	if enum, ok := sch["enum"].([]interface{}); ok && len(enum) > 0 {
+		return enum[0], true
+	}
+	return nil, false
+}
+
+func (s *Spec) mediaExample(media map[string]interface{}) (interface{}, bool) {
+	if example, ok := s.example(media); ok {
+		return example, true
+	}
+	return s.schemaExample(media["schema"], 0)
+}
+
+// example gets the example (or the first of the examples)
+// of an object.
+func (s *Spec) example(node map[string]interface{}) (interface{}, bool) {
+	if example, ok := node["example"]; ok {
+		return example, true
+	}
+	examples := s.object(node["examples"])
+	names := make([]string, 0, len(examples))
+	for name := range examples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if example := s.object(examples[name]); example != nil {
+			if value, ok := example["value"]; ok {
+				return value, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// schemaExample builds an example from the examples in a schema
+// and its properties.
+func (s *Spec) schemaExample(node interface{}, depth int) (interface{}, bool) {
+	sch := s.object(node)
+	if sch == nil || depth > 8 {
+		return nil, false
+	}
+	if example, ok := sch["example"]; ok {
+		return example, true
+	}
+	if def, ok := sch["default"]; ok {
+		return def, true
+	}
+	if all, ok := sch["allOf"].([]interface{}); ok {
+		merged := make(map[string]interface{})
+		for _, sub := range all {
+			if example, ok := s.schemaExample(sub, depth+1); ok {
+				if m, ok := example.(map[string]interface{}); ok {
+					for k, v := range m {
+						merged[k] = v
+					}
+				}
+			}
+		}
+		return merged, len(merged) > 0
+	}
+	switch sch["type"] {
+	case "object":
+		properties := s.object(sch["properties"])
+		example := make(map[string]interface{})
+		for name, property := range properties {
+			if value, ok := s.schemaExample(property, depth+1); ok {
+				example[name] = value
+			}
+		}
+		return example, len(example) > 0
+	case "array":
+		if item, ok := s.schemaExample(sch["items"], depth+1); ok {
+			return []interface{}{item}, true
+		}
+	}
+	return nil, false
+}
+
+// formatExample gets the body and code block type of an example.
+func formatExample(mediaType string, example interface{}) (string, string) {
+	if s, ok := example.(string); ok && !isJSON(mediaType) {
+		return s, ""
+	}
+	b, err := json.MarshalIndent(example, "", "\t")
+	if err != nil {
+		return fmt.Sprint(example), ""
+	}
+	return string(b), "json"
+}
+
+// fileName gets a unique document name for a tag or path,
+// like users.silk.md.
+func fileName(title string, names map[string]bool) string {
+	base := slug(title)
+	name := base + ".silk.md"
+	for i := 2; names[name]; i++ {
+		name = fmt.Sprintf("%s-%d.silk.md", base, i)
+	}
+	names[name] = true
+	return name
+}
+
+var slugRegexp = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slug makes a file name from a tag or path, like
+// users-id from /users/{id}.
+func slug(s string) string {
+	s = strings.Trim(slugRegexp.ReplaceAllString(strings.ToLower(s), "-"), "-")
+	if len(s) == 0 {
+		return "root"
+	}
+	return s
+}
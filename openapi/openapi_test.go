@@ -0,0 +1,257 @@
+package openapi_test
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/cheekybits/is"
+	"github.com/matryer/silk/document"
+	"github.com/matryer/silk/openapi"
+	"github.com/matryer/silk/parse"
+)
+
+func TestLoad(t *testing.T) {
+	is := is.New(t)
+	spec, err := openapi.Load("../testfiles/success/openapi.yaml")
+	is.NoErr(err)
+	is.Equal(spec.BasePath, "/v1")
+	is.Equal(len(spec.Operations), 4)
+	op := spec.Operations[0]
+	is.Equal(op.Method, "GET")
+	is.Equal(op.Path, "/users")
+	is.Equal(op.ID, "listUsers")
+	is.Equal(op.Summary, "List users")
+	is.Equal(len(op.Tags), 1)
+	is.Equal(op.Tags[0], "users")
+	is.Equal(len(spec.Operations[2].Params()), 1)
+}
+
+func TestLoadNotOpenAPI(t *testing.T) {
+	is := is.New(t)
+	_, err := openapi.Load("../testfiles/success/schemas/user.json")
+	is.Err(err)
+}
+
+func TestFind(t *testing.T) {
+	is := is.New(t)
+	spec, err := openapi.Parse([]byte(`{
+		"openapi": "3.0.0",
+		"paths": {
+			"/users/{id}": {"get": {"operationId": "getUser"}},
+			"/users/me": {"get": {"operationId": "getMe"}},
+			"/files/{name}.json": {"get": {"operationId": "getFile"}}
+		}
+	}`), "spec.json")
+	is.NoErr(err)
+	is.Equal(spec.Find("GET", "/users/1").ID, "getUser")
+	is.Equal(spec.Find("get", "/users/1/").ID, "getUser")
+	is.Equal(spec.Find("GET", "/users/me").ID, "getMe")
+	is.Equal(spec.Find("GET", "/files/readme.json").ID, "getFile")
+	is.Nil(spec.Find("POST", "/users/1"))
+	is.Nil(spec.Find("GET", "/users/1/comments"))
+}
+
+func TestCheckRequest(t *testing.T) {
+	is := is.New(t)
+	spec, err := openapi.Load("../testfiles/success/openapi.yaml")
+	is.NoErr(err)
+
+	req, err := http.NewRequest("GET", "http://localhost/v1/users?limit=2", nil)
+	is.NoErr(err)
+	op := spec.Find(req.Method, req.URL.Path)
+	is.NotNil(op)
+	is.Equal(len(op.CheckRequest(req, nil)), 0)
+
+	req, err = http.NewRequest("GET", "http://localhost/v1/users/abc?verbose=true", nil)
+	is.NoErr(err)
+	op = spec.Find(req.Method, req.URL.Path)
+	is.NotNil(op)
+	violations := op.CheckRequest(req, nil)
+	is.Equal(len(violations), 2)
+	is.Equal(violations[0].String(), `path parameter "id": expected integer but got string`)
+	is.Equal(violations[1].String(), `query parameter "verbose": not documented`)
+
+	body := `{"name": "Silk", "email": "nope"}`
+	req, err = http.NewRequest("POST", "http://localhost/users", strings.NewReader(body))
+	is.NoErr(err)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	op = spec.Find(req.Method, req.URL.Path)
+	is.NotNil(op)
+	violations = op.CheckRequest(req, []byte(body))
+	is.Equal(len(violations), 1)
+	is.Equal(violations[0].String(), `request body /email: must be a valid email`)
+
+	violations = op.CheckRequest(req, nil)
+	is.Equal(len(violations), 1)
+	is.Equal(violations[0].String(), `request body: missing required body`)
+
+	req.Header.Set("Content-Type", "text/plain")
+	violations = op.CheckRequest(req, []byte(body))
+	is.Equal(len(violations), 1)
+	is.Equal(violations[0].String(), `request body: Content-Type "text/plain" not documented`)
+}
+
+func TestCheckResponse(t *testing.T) {
+	is := is.New(t)
+	spec, err := openapi.Load("../testfiles/success/openapi.yaml")
+	is.NoErr(err)
+	op := spec.Find("GET", "/users")
+	is.NotNil(op)
+
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	header.Set("X-Request-Id", "abc")
+	is.Equal(len(op.CheckResponse(200, header, []byte(`[{"id": 1, "name": "Mat", "email": "mat@example.com"}]`))), 0)
+
+	violations := op.CheckResponse(200, header, []byte(`[{"id": "1", "name": "Mat"}]`))
+	is.Equal(len(violations), 2)
+	is.Equal(violations[0].String(), `response body /0: missing required property "email"`)
+	is.Equal(violations[1].String(), `response body /0/id: expected integer but got string`)
+
+	header.Del("X-Request-Id")
+	violations = op.CheckResponse(500, header, nil)
+	is.Equal(len(violations), 1)
+	is.Equal(violations[0].String(), `response status: 500 not documented`)
+	violations = op.CheckResponse(200, header, nil)
+	is.Equal(len(violations), 1)
+	is.Equal(violations[0].String(), `response header "X-Request-Id": missing required header`)
+
+	op = spec.Find("DELETE", "/users/1")
+	is.NotNil(op)
+	is.NotNil(op.Response(404))
+	is.Nil(op.Response(500))
+}
+
+func TestGenerate(t *testing.T) {
+	is := is.New(t)
+	spec, err := openapi.Load("../testfiles/success/openapi.yaml")
+	is.NoErr(err)
+	files := openapi.Generate(spec)
+	is.Equal(len(files), 1)
+	is.Equal(files[0].Name, "users.silk.md")
+	var buf bytes.Buffer
+	is.NoErr(document.Write(&buf, files[0].Groups...))
+	groups, err := parse.Parse(files[0].Name, &buf)
+	is.NoErr(err)
+	is.Equal(len(groups), 1)
+	is.Equal(string(groups[0].Title), "users")
+	is.Equal(len(groups[0].Requests), 4)
+
+	list := groups[0].Requests[0]
+	is.Equal(string(list.Method), "GET")
+	is.Equal(string(list.Path), "/users")
+	is.Equal(list.Params[0].Detail().Key, "limit")
+	is.Equal(list.Params[0].Detail().Value.Data, 1.0)
+	is.Equal(list.ExpectedDetails[0].Detail().Value.Data, 200.0)
+	is.Equal(list.ExpectedBodyType, "json")
+
+	create := groups[0].Requests[1]
+	is.Equal(string(create.Method), "POST")
+	is.Equal(create.Details[0].Detail().Value.Data, "application/json")
+	is.True(strings.Contains(create.Body.String(), `"email": "silk@example.com"`))
+	is.Equal(create.ExpectedDetails[0].Detail().Value.Data, 201.0)
+
+	get := groups[0].Requests[2]
+	is.Equal(string(get.Path), "/users/1")
+	is.True(strings.Contains(get.ExpectedBody.String(), `"name": "Mat"`))
+
+	del := groups[0].Requests[3]
+	is.Equal(string(del.Method), "DELETE")
+	is.Equal(del.ExpectedDetails[0].Detail().Value.Data, 204.0)
+}
+
+func TestGenerateSameSlug(t *testing.T) {
+	is := is.New(t)
+	spec, err := openapi.Parse([]byte(`{
+		"openapi": "3.0.0",
+		"paths": {
+			"/users": {"get": {"tags": ["Users"]}},
+			"/people": {"get": {"tags": ["users"]}},
+			"/members": {"get": {"tags": ["Users"]}}
+		}
+	}`), "spec.json")
+	is.NoErr(err)
+	files := openapi.Generate(spec)
+	is.Equal(len(files), 2)
+	names := map[string]string{}
+	for _, file := range files {
+		names[file.Groups[0].Title] = file.Name
+	}
+	is.Equal(names["Users"], "users.silk.md")
+	is.Equal(names["users"], "users-2.silk.md")
+}
+
+func TestGeneratePathExamples(t *testing.T) {
+	is := is.New(t)
+	spec, err := openapi.Parse([]byte(`{
+		"openapi": "3.0.0",
+		"paths": {
+			"/files/{name}": {"get": {"parameters": [
+				{"name": "name", "in": "path", "required": true, "example": "a b/c#d?"}
+			]}}
+		}
+	}`), "spec.json")
+	is.NoErr(err)
+	files := openapi.Generate(spec)
+	is.Equal(len(files), 1)
+	is.Equal(files[0].Groups[0].Requests[0].Path, "/files/a%20b%2Fc%23d%3F")
+}
+
+func TestExport(t *testing.T) {
+	is := is.New(t)
+	groups, err := parse.ParseFile("../testfiles/success/comments.silk.md", "../testfiles/success/users.silk.md")
+	is.NoErr(err)
+	d := openapi.Export(openapi.Info{Title: "Silk", Version: "1.0"}, groups...)
+	b, err := d.YAML()
+	is.NoErr(err)
+
+	// the exported document can be loaded as a spec
+	spec, err := openapi.Parse(b, "exported.yaml")
+	is.NoErr(err)
+	is.Equal(len(spec.Operations), 8)
+
+	op := spec.Find("GET", "/comments/123")
+	is.NotNil(op)
+	is.Equal(op.Path, "/comments/{id}")
+	is.Equal(op.Tags[0], "Comments and things")
+	is.Equal(op.Summary, "Read a single comment with the specified `{id}`.")
+	params := op.Params()
+	is.Equal(len(params), 2)
+	is.Equal(params[0]["name"], "id")
+	is.Equal(params[0]["in"], "path")
+	is.Equal(params[1]["name"], "pretty")
+	is.Equal(params[1]["in"], "query")
+	is.Equal(params[1]["example"], true)
+
+	op = spec.Find("POST", "/comments")
+	is.NotNil(op)
+	response := op.Response(201)
+	is.NotNil(response)
+	is.Equal(response["description"], "### Example response")
+	content := response["content"].(map[string]interface{})
+	example := content["application/json"].(map[string]interface{})["example"]
+	is.Equal(example.(map[string]interface{})["name"], "Mat")
+
+	op = spec.Find("GET", "/users/3")
+	is.NotNil(op)
+	is.Nil(op.Response(200))
+	is.NotNil(op.Response(404))
+	is.Equal(op.Response(404)["description"], "Not Found")
+
+	op = spec.Find("POST", "/users")
+	is.NotNil(op)
+	headers := op.Response(201)["headers"].(map[string]interface{})
+	is.NotNil(headers["Location"])
+	body := []byte(`{"name": "Silk", "email": "silk@example.com"}`)
+	req, err := http.NewRequest("POST", "/users", bytes.NewReader(body))
+	is.NoErr(err)
+	req.Header.Set("Content-Type", "application/json")
+	is.Equal(len(op.CheckRequest(req, body)), 0)
+
+	b, err = d.JSON()
+	is.NoErr(err)
+	_, err = openapi.Parse(b, "exported.json")
+	is.NoErr(err)
+}
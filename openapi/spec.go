@@ -0,0 +1,272 @@
+package openapi
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/matryer/silk/schema"
+)
+
+// methods are the operation methods of a path item, in the
+// order operations are listed.
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// Spec is an OpenAPI 3 specification.
+type Spec struct {
+	doc    map[string]interface{}
+	schema *schema.Schema
+	// BasePath is the path of the first server URL, which
+	// request paths may begin with.
+	BasePath string
+	// Operations are the operations in the spec, ordered
+	// by path.
+	Operations []*Operation
+}
+
+// Operation is an operation in the spec.
+type Operation struct {
+	// Method is the upper case HTTP method.
+	Method string
+	// Path is the path template, like /users/{id}.
+	Path        string
+	ID          string
+	Summary     string
+	Description string
+	Tags        []string
+
+	spec   *Spec
+	node   map[string]interface{}
+	params []map[string]interface{}
+	// pathRegexp matches request paths, capturing
+	// the path parameters in pathParams.
+	pathRegexp *regexp.Regexp
+	pathParams []string
+	// literal is the number of literal characters in the
+	// path, so more specific paths are preferred.
+	literal int
+}
+
+// Load loads the spec in the JSON or YAML file at path.
+func Load(path string) (*Spec, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(b, path)
+}
+
+// Parse parses a spec as if it were in the file at path.
+// Schema references to other files are resolved relative to path.
+func Parse(b []byte, path string) (*Spec, error) {
+	doc, err := schema.Decode(b, path)
+	if err != nil {
+		return nil, err
+	}
+	root, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: not an OpenAPI document", path)
+	}
+	if version, _ := root["openapi"].(string); !strings.HasPrefix(version, "3.") {
+		return nil, fmt.Errorf("%s: not an OpenAPI 3 document", path)
+	}
+	s := &Spec{doc: root, schema: schema.New(doc, path)}
+	if servers, ok := root["servers"].([]interface{}); ok && len(servers) > 0 {
+		if server, ok := servers[0].(map[string]interface{}); ok {
+			u, _ := server["url"].(string)
+			s.BasePath = serverPath(u)
+		}
+	}
+	paths, _ := root["paths"].(map[string]interface{})
+	templates := make([]string, 0, len(paths))
+	for template := range paths {
+		templates = append(templates, template)
+	}
+	sort.Strings(templates)
+	for _, template := range templates {
+		item := s.object(paths[template])
+		for _, method := range methods {
+			node := s.object(item[method])
+			if node == nil {
+				continue
+			}
+			op, err := s.newOperation(strings.ToUpper(method), template, item, node)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s", path, err)
+			}
+			s.Operations = append(s.Operations, op)
+		}
+	}
+	return s, nil
+}
+
+func (s *Spec) newOperation(method, path string, item, node map[string]interface{}) (*Operation, error) {
+	op := &Operation{
+		Method: method,
+		Path:   path,
+		spec:   s,
+		node:   node,
+	}
+	op.ID, _ = node["operationId"].(string)
+	op.Summary, _ = node["summary"].(string)
+	op.Description, _ = node["description"].(string)
+	if tags, ok := node["tags"].([]interface{}); ok {
+		for _, tag := range tags {
+			op.Tags = append(op.Tags, fmt.Sprint(tag))
+		}
+	}
+	// operation parameters override path item parameters
+	// with the same name and location
+	seen := make(map[string]bool)
+	for _, list := range []interface{}{node["parameters"], item["parameters"]} {
+		params, _ := list.([]interface{})
+		for _, p := range params {
+			param := s.object(p)
+			if param == nil {
+				continue
+			}
+			key := fmt.Sprint(param["in"], ":", param["name"])
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			op.params = append(op.params, param)
+		}
+	}
+	pattern, names, literal := pathPattern(path)
+	var err error
+	if op.pathRegexp, err = regexp.Compile(pattern); err != nil {
+		return nil, fmt.Errorf("invalid path %q: %s", path, err)
+	}
+	op.pathParams = names
+	op.literal = literal
+	return op, nil
+}
+
+// Find gets the operation for the method and request path, or
+// nil if there isn't one.
+// The path may begin with the spec's BasePath.
+func (s *Spec) Find(method, path string) *Operation {
+	if len(s.BasePath) > 0 && strings.HasPrefix(path, s.BasePath) {
+		if op := s.find(method, path[len(s.BasePath):]); op != nil {
+			return op
+		}
+	}
+	return s.find(method, path)
+}
+
+func (s *Spec) find(method, path string) *Operation {
+	path = trimSlash(path)
+	var found *Operation
+	for _, op := range s.Operations {
+		if op.Method != strings.ToUpper(method) || !op.pathRegexp.MatchString(path) {
+			continue
+		}
+		if found == nil || op.literal > found.literal {
+			found = op
+		}
+	}
+	return found
+}
+
+// Params gets the parameters of the operation, including those
+// of its path item.
+func (o *Operation) Params() []map[string]interface{} {
+	return o.params
+}
+
+// Node gets the decoded operation object.
+func (o *Operation) Node() map[string]interface{} {
+	return o.node
+}
+
+// pathValues gets the values of the path parameters in path.
+func (o *Operation) pathValues(path string) map[string]string {
+	spec := o.spec
+	if len(spec.BasePath) > 0 && strings.HasPrefix(path, spec.BasePath) && o.pathRegexp.MatchString(trimSlash(path[len(spec.BasePath):])) {
+		path = path[len(spec.BasePath):]
+	}
+	values := make(map[string]string)
+	matches := o.pathRegexp.FindStringSubmatch(trimSlash(path))
+	for i, name := range o.pathParams {
+		if i+1 < len(matches) {
+			values[name] = matches[i+1]
+		}
+	}
+	return values
+}
+
+// Resolve follows any local $ref (like #/components/schemas/user)
+// in node, and gets the value it refers to.
+func (s *Spec) Resolve(node interface{}) interface{} {
+	for i := 0; i < 32; i++ {
+		m, ok := node.(map[string]interface{})
+		if !ok {
+			return node
+		}
+		ref, ok := m["$ref"].(string)
+		if !ok || !strings.HasPrefix(ref, "#") {
+			return node
+		}
+		var err error
+		if node, err = schema.Pointer(s.doc, ref[1:]); err != nil {
+			return nil
+		}
+	}
+	return nil
+}
+
+// object resolves node and gets it as an object, or nil
+// if it isn't one.
+func (s *Spec) object(node interface{}) map[string]interface{} {
+	m, _ := s.Resolve(node).(map[string]interface{})
+	return m
+}
+
+var pathParamRegexp = regexp.MustCompile(`{([^}]+)}`)
+
+// pathPattern makes a regular expression that matches paths
+// like the template /users/{id}, and gets the names of the
+// parameters and the number of literal characters.
+func pathPattern(template string) (string, []string, int) {
+	template = trimSlash(template)
+	var names []string
+	var pattern []string
+	literal := 0
+	last := 0
+	for _, loc := range pathParamRegexp.FindAllStringSubmatchIndex(template, -1) {
+		pattern = append(pattern, regexp.QuoteMeta(template[last:loc[0]]), "([^/]+)")
+		literal += loc[0] - last
+		names = append(names, template[loc[2]:loc[3]])
+		last = loc[1]
+	}
+	pattern = append(pattern, regexp.QuoteMeta(template[last:]))
+	literal += len(template) - last
+	return "^" + strings.Join(pattern, "") + "$", names, literal
+}
+
+// serverPath gets the path of a server URL, like
+// /v1 from https://{host}/v1/.
+func serverPath(u string) string {
+	if i := strings.Index(u, "://"); i > -1 {
+		u = u[i+3:]
+		i = strings.Index(u, "/")
+		if i == -1 {
+			return ""
+		}
+		u = u[i:]
+	}
+	if u == "/" {
+		return ""
+	}
+	return strings.TrimSuffix(u, "/")
+}
+
+func trimSlash(path string) string {
+	if len(path) > 1 {
+		return strings.TrimSuffix(path, "/")
+	}
+	return path
+}
@@ -20,7 +20,7 @@ func parseDetail(b []byte, detailregex *regexp.Regexp) (*Detail, error) {
 	if err != nil {
 		panic("silk: failed to parse detail: " + err.Error())
 	}
-	sep := bytes.IndexAny(detail, ":=")
+	sep := separator(detail)
 	if sep == -1 || sep > len(detail)-1 {
 		return nil, errors.New("malformed detail")
 	}
@@ -31,6 +31,31 @@ func parseDetail(b []byte, detailregex *regexp.Regexp) (*Detail, error) {
 	}, nil
 }
 
+// separator gets the index of the : or = that separates
+// the key from the value, ignoring any inside [brackets] or
+// (parentheses) so keys like Data.items[?(@.id==3)] and
+// XPath(//item[@id='1']) are kept whole.
+func separator(detail []byte) int {
+	depth := 0
+	for i, c := range detail {
+		switch c {
+		case '[', '(':
+			depth++
+		case ']', ')':
+			depth--
+		case ':', '=':
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	// unbalanced brackets
+	if i := bytes.Index(detail, []byte(": ")); i > -1 {
+		return i
+	}
+	return bytes.IndexAny(detail, ":=")
+}
+
 func (d *Detail) String() string {
 	valbytes, err := json.Marshal(d.Value.Data)
 	if err != nil {
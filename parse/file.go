@@ -0,0 +1,66 @@
+package parse
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+)
+
+// File is a reference to a file made by a document,
+// with a Body detail (* Body: @fixtures/user.json) or
+// a code block (```json @fixtures/user.json).
+type File struct {
+	// Path is the path to the file, resolved relative
+	// to the document that referenced it.
+	Path string
+	// Line is the line number of the reference.
+	Line int
+}
+
+// newFile makes a File for the reference (without the @ prefix)
+// made by the specified document.
+func newFile(filename, ref string, line int) *File {
+	path := ref
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(filename), path)
+	}
+	return &File{Path: path, Line: line}
+}
+
+// bodyFileRef gets the file referenced by a Body detail line,
+// or an empty string if the line is not a file reference.
+// Only unquoted values are references, so * Body: "@silk" is
+// a string.
+func bodyFileRef(line *Line) string {
+	detail := line.Detail()
+	if detail == nil || detail.Key != "Body" {
+		return ""
+	}
+	sep := separator(line.Bytes)
+	if sep == -1 {
+		return ""
+	}
+	value := clean(line.Bytes[sep+1:])
+	if !bytes.HasPrefix(value, []byte("@")) {
+		return ""
+	}
+	return string(value[1:])
+}
+
+// splitCodeblockType splits the text after the opening
+// back tics of a code block into the body type and any file
+// reference:
+//
+//	```json @fixtures/user.json
+func splitCodeblockType(b []byte) (string, string) {
+	var bodyType []string
+	var ref string
+	for _, field := range bytes.Fields(b) {
+		if bytes.HasPrefix(field, []byte("@")) {
+			ref = string(field[1:])
+			continue
+		}
+		bodyType = append(bodyType, string(field))
+	}
+	return strings.Join(bodyType, " "), ref
+}
@@ -28,10 +28,9 @@ func ParseLine(n int, unsafeText []byte) (*Line, error) {
 	var comment []byte
 	text := make([]byte, len(unsafeText))
 	copy(text, unsafeText)
-	if bytes.Contains(text, commentPrefix) {
-		segs := bytes.Split(text, commentPrefix)
-		text = segs[0]
-		comment = segs[1]
+	if i := commentIndex(text); i > -1 {
+		comment = text[i+len(commentPrefix):]
+		text = text[:i]
 	}
 	var rx *regexp.Regexp
 	for _, item := range matchTypes {
@@ -43,7 +42,7 @@ func ParseLine(n int, unsafeText []byte) (*Line, error) {
 	}
 	// parse the detail now
 	var d *Detail
-	if linetype == LineTypeDetail || linetype == LineTypeParam {
+	if linetype == LineTypeDetail || linetype == LineTypeParam || linetype == LineTypePart || linetype == LineTypeFormField {
 		var err error
 		d, err = parseDetail(text, rx)
 		if err != nil {
@@ -60,6 +59,29 @@ func ParseLine(n int, unsafeText []byte) (*Line, error) {
 	}, nil
 }
 
+// commentIndex gets the index of the // that begins a comment,
+// or -1 if there isn't one. Any inside [brackets] or (parentheses)
+// are ignored, so keys like XPath(a //b) are kept whole.
+func commentIndex(text []byte) int {
+	depth := 0
+	for i, c := range text {
+		switch c {
+		case '[', '(':
+			depth++
+		case ']', ')':
+			depth--
+		}
+		if depth == 0 && bytes.HasPrefix(text[i:], commentPrefix) {
+			return i
+		}
+	}
+	if depth != 0 {
+		// unbalanced brackets
+		return bytes.Index(text, commentPrefix)
+	}
+	return -1
+}
+
 func (l *Line) String() string {
 	return fmt.Sprintf("%d: (%s) %s", l.Number, l.Type, string(l.Bytes))
 }
@@ -126,6 +148,8 @@ const (
 	LineTypeDetail
 	LineTypeSeparator
 	LineTypeParam
+	LineTypePart
+	LineTypeFormField
 )
 
 var lineTypeStrs = map[LineType]string{
@@ -136,6 +160,8 @@ var lineTypeStrs = map[LineType]string{
 	LineTypeDetail:       "detail",
 	LineTypeSeparator:    "separator",
 	LineTypeParam:        "param",
+	LineTypePart:         "part",
+	LineTypeFormField:    "formfield",
 }
 
 func (l LineType) String() string {
@@ -171,6 +197,15 @@ var matchTypes = []struct {
 	// * ?param=value
 	R:    "^\\s*\\* `?\\?(.*=?.*)`?",
 	Type: LineTypeParam,
+}, {
+	// * +field=value
+	// * +file=@path/to/file.png
+	R:    "^\\s*\\* `?\\+(.*=?.*)`?",
+	Type: LineTypePart,
+}, {
+	// * &field=value
+	R:    "^\\s*\\* `?&(.*=?.*)`?",
+	Type: LineTypeFormField,
 }, {
 	// * Content-Type: application/json
 	R:    "^\\s*\\* (.*)",
@@ -47,6 +47,18 @@ func TestParseLine(t *testing.T) {
 	}, {
 		Src:  "* ?param=value",
 		Type: parse.LineTypeParam,
+	}, {
+		Src:  "* +field=value",
+		Type: parse.LineTypePart,
+	}, {
+		Src:  "* `+file=@path/to/file.png`",
+		Type: parse.LineTypePart,
+	}, {
+		Src:  "* &field=value",
+		Type: parse.LineTypeFormField,
+	}, {
+		Src:  "  * `&field=value`",
+		Type: parse.LineTypeFormField,
 	}, {
 		Src:  "* Cookie: name=value",
 		Type: parse.LineTypeDetail,
@@ -102,6 +114,23 @@ func TestLineComments(t *testing.T) {
 	is.Equal(l.Capture(), "id")
 }
 
+func TestLineCommentsInBrackets(t *testing.T) {
+	is := is.New(t)
+	l, err := parse.ParseLine(0, []byte(`* XPath( //a): "Silk" // the {name}`))
+	is.NoErr(err)
+	is.Equal(l.Detail().Key, "XPath( //a)")
+	is.Equal(l.Detail().Value.Data, "Silk")
+	is.Equal(l.Capture(), "name")
+	l, err = parse.ParseLine(0, []byte(`* XPath(a //b[@id='1']): "Silk"`))
+	is.NoErr(err)
+	is.Equal(l.Detail().Key, "XPath(a //b[@id='1'])")
+	is.Equal(len(l.Comment), 0)
+	// unbalanced brackets still have comments
+	l, err = parse.ParseLine(0, []byte(`* Key: "(Value" // comment`))
+	is.NoErr(err)
+	is.Equal(string(l.Comment), " comment")
+}
+
 func TestLineParams(t *testing.T) {
 	is := is.New(t)
 	for i, line := range []string{
@@ -119,6 +148,17 @@ func TestLineParams(t *testing.T) {
 	}
 }
 
+func TestLineParts(t *testing.T) {
+	is := is.New(t)
+	l, err := parse.ParseLine(0, []byte("* +avatar=@images/silk.png;type=image/png"))
+	is.NoErr(err)
+	is.Equal(l.Type, parse.LineTypePart)
+	detail := l.Detail()
+	is.OK(detail)
+	is.Equal(detail.Key, "avatar")
+	is.Equal(detail.Value.Data, "@images/silk.png;type=image/png")
+}
+
 func TestLineDetail(t *testing.T) {
 	is := is.New(t)
 	l, err := parse.ParseLine(0, []byte(`* Key-Here: "Value"`))
@@ -128,6 +168,15 @@ func TestLineDetail(t *testing.T) {
 	is.Equal(detail.Value.Data, "Value")
 }
 
+func TestLineDetailQuery(t *testing.T) {
+	is := is.New(t)
+	l, err := parse.ParseLine(0, []byte(`* Data.items[?(@.id==3 && @.name=~/a:b/)].name: "Lamp"`))
+	is.NoErr(err)
+	detail := l.Detail()
+	is.Equal(detail.Key, "Data.items[?(@.id==3 && @.name=~/a:b/)].name")
+	is.Equal(detail.Value.Data, "Lamp")
+}
+
 func TestLinesReader(t *testing.T) {
 	is := is.New(t)
 
@@ -15,6 +15,9 @@ var (
 	errMissingEndCodeblock = errors.New("missing end codeblock")
 	errUnexpectedDetails   = errors.New("unexpected details")
 	errUnexpectedParams    = errors.New("unexpected params")
+	errUnexpectedParts     = errors.New("unexpected form parts")
+	errUnexpectedForm      = errors.New("unexpected form fields")
+	errFileCodeblock       = errors.New("codeblock referencing a file must be empty")
 	errMalformedDetail     = errors.New("malformed detail")
 )
 
@@ -24,21 +27,33 @@ type Group struct {
 	Title    []byte
 	Requests []*Request
 	Details  Lines
+	// Description is the plain text before the first request.
+	Description Lines
 }
 
 // Request describes an HTTP request and a set of
 // associated assertions.
 type Request struct {
+	// Line is the line number of the request heading.
+	Line     int
 	Path     []byte
 	Method   []byte
 	Details  Lines
 	Params   Lines
+	Parts    Lines
+	Form     Lines
 	Body     Lines
 	BodyType string
+	BodyFile *File
+	// Description is the plain text before the separator.
+	Description Lines
 	//===
 	ExpectedBody     Lines
 	ExpectedBodyType string
+	ExpectedBodyFile *File
 	ExpectedDetails  Lines
+	// ExpectedDescription is the plain text after the separator.
+	ExpectedDescription Lines
 }
 
 // ErrLine describes an error at a specific line.
@@ -122,7 +137,7 @@ func Parse(filename string, r io.Reader) ([]*Group, error) {
 			}
 			settingExpectations = false
 			var err error
-			currentRequest = &Request{}
+			currentRequest = &Request{Line: n}
 			matches := line.Regexp.FindSubmatch(line.Bytes)
 			if currentRequest.Method, err = getok(matches, 1); err != nil {
 				return nil, &ErrLine{N: n, Err: err}
@@ -136,10 +151,11 @@ func Parse(filename string, r io.Reader) ([]*Group, error) {
 				return nil, &ErrLine{N: n, Err: errUnexpectedCodeblock}
 			}
 
-			var bodyType string
+			var bodyType, ref string
 			if len(line.Bytes) > 3 {
-				bodyType = string(line.Bytes[3:])
+				bodyType, ref = splitCodeblockType(line.Bytes[3:])
 			}
+			start := n
 
 			var lines Lines
 			var err error
@@ -147,12 +163,21 @@ func Parse(filename string, r io.Reader) ([]*Group, error) {
 			if err != nil {
 				return nil, &ErrLine{N: n, Err: err}
 			}
+			var file *File
+			if len(ref) > 0 {
+				if len(lines) > 0 {
+					return nil, &ErrLine{N: start, Err: errFileCodeblock}
+				}
+				file = newFile(filename, ref, start)
+			}
 			if settingExpectations {
 				currentRequest.ExpectedBody = lines
 				currentRequest.ExpectedBodyType = bodyType
+				currentRequest.ExpectedBodyFile = file
 			} else {
 				currentRequest.Body = lines
 				currentRequest.BodyType = bodyType
+				currentRequest.BodyFile = file
 			}
 
 		case LineTypeDetail:
@@ -163,6 +188,15 @@ func Parse(filename string, r io.Reader) ([]*Group, error) {
 				currentGroup.Details = append(currentGroup.Details, line)
 				continue
 			}
+			if ref := bodyFileRef(line); len(ref) > 0 {
+				// * Body: @path/to/file
+				if settingExpectations {
+					currentRequest.ExpectedBodyFile = newFile(filename, ref, n)
+				} else {
+					currentRequest.BodyFile = newFile(filename, ref, n)
+				}
+				continue
+			}
 			if settingExpectations {
 				currentRequest.ExpectedDetails = append(currentRequest.ExpectedDetails, line)
 			} else {
@@ -176,8 +210,27 @@ func Parse(filename string, r io.Reader) ([]*Group, error) {
 				return nil, &ErrLine{N: n, Err: errUnexpectedParams}
 			}
 			currentRequest.Params = append(currentRequest.Params, line)
+		case LineTypePart:
+			if currentRequest == nil || settingExpectations {
+				return nil, &ErrLine{N: n, Err: errUnexpectedParts}
+			}
+			currentRequest.Parts = append(currentRequest.Parts, line)
+		case LineTypeFormField:
+			if currentRequest == nil || settingExpectations {
+				return nil, &ErrLine{N: n, Err: errUnexpectedForm}
+			}
+			currentRequest.Form = append(currentRequest.Form, line)
 		case LineTypeSeparator:
 			settingExpectations = true
+		case LineTypePlain:
+			switch {
+			case currentRequest != nil && settingExpectations:
+				currentRequest.ExpectedDescription = append(currentRequest.ExpectedDescription, line)
+			case currentRequest != nil:
+				currentRequest.Description = append(currentRequest.Description, line)
+			case currentGroup != nil:
+				currentGroup.Description = append(currentGroup.Description, line)
+			}
 		}
 
 	}
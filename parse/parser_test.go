@@ -1,6 +1,7 @@
 package parse_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/cheekybits/is"
@@ -27,6 +28,7 @@ func TestParser(t *testing.T) {
 	is.Equal(group.Details[0].Detail().Value.Data, "http://localhost:8080/")
 
 	req1 := group.Requests[0]
+	is.Equal(req1.Line, 5)
 	is.Equal("POST", string(req1.Method))
 	is.Equal("/comments", string(req1.Path))
 	is.Equal(len(req1.Details), 1)
@@ -45,8 +47,12 @@ func TestParser(t *testing.T) {
   "comment": "Good work"
 }`)
 	is.Equal(req1.ExpectedBodyType, "json")
+	is.Equal(strings.TrimSpace(req1.Description.String()), "Create a comment.\n\n### Example request")
+	is.Equal(strings.TrimSpace(req1.ExpectedDescription.String()), "### Example response")
+	is.Equal(strings.TrimSpace(group.Description.String()), "")
 
 	req2 := group.Requests[1]
+	is.Equal(req2.Line, 34)
 	is.Equal("GET", req2.Method)
 	is.Equal("/comments/{id}", req2.Path)
 	is.Equal(len(req2.Params), 1)
@@ -67,3 +73,40 @@ func TestParser(t *testing.T) {
 	is.Equal(len(group.Requests), 1)
 
 }
+
+func TestParserBodyFiles(t *testing.T) {
+	is := is.New(t)
+
+	groups, err := parse.ParseFile("../testfiles/success/body-files.silk.md")
+	is.NoErr(err)
+	is.Equal(len(groups), 1)
+	is.Equal(len(groups[0].Requests), 3)
+
+	req1 := groups[0].Requests[0]
+	is.OK(req1.BodyFile)
+	is.Equal(req1.BodyFile.Path, "../testfiles/success/fixtures/create-user.json")
+	is.Equal(req1.BodyFile.Line, 7)
+	is.Equal(len(req1.Details), 1)
+	is.OK(req1.ExpectedBodyFile)
+	is.Equal(req1.ExpectedBodyFile.Path, "../testfiles/success/fixtures/create-user.json")
+	is.Equal(len(req1.ExpectedDetails), 1)
+
+	req2 := groups[0].Requests[1]
+	is.OK(req2.BodyFile)
+	is.Equal(req2.BodyType, "json")
+	is.Equal(req2.ExpectedBodyType, "json")
+	is.Equal(req2.ExpectedBodyFile.Path, "../testfiles/success/fixtures/user.json")
+
+	_, err = parse.Parse("bad.silk.md", strings.NewReader("# Group\n## GET /\n```json @file.json\n{}\n```"))
+	is.Err(err)
+
+	// quoted values are strings, not files
+	groups, err = parse.Parse("quoted.silk.md", strings.NewReader("# Group\n## POST /\n* Body: \"@silk\"\n===\n* Body: \"@silk\""))
+	is.NoErr(err)
+	req := groups[0].Requests[0]
+	is.Nil(req.BodyFile)
+	is.Nil(req.ExpectedBodyFile)
+	is.Equal(len(req.Details), 1)
+	is.Equal(req.Details[0].Detail().Value.Data, "@silk")
+	is.Equal(len(req.ExpectedDetails), 1)
+}
@@ -3,6 +3,7 @@ package parse
 import (
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"regexp"
 	"strings"
 )
@@ -18,7 +19,7 @@ func isRegex(v interface{}) bool {
 	if !ok {
 		return false
 	}
-	return strings.HasPrefix(s, `/`) && strings.HasSuffix(s, `/`)
+	return len(s) > 1 && strings.HasPrefix(s, `/`) && strings.HasSuffix(s, `/`)
 }
 
 // Value wraps any kind of data and provides helpers
@@ -44,7 +45,7 @@ func (v Value) Equal(val interface{}) bool {
 	var str string
 	var ok bool
 	if str, ok = v.Data.(string); !ok {
-		return v.Data == val
+		return reflect.DeepEqual(v.Data, val)
 	}
 	if isRegex(str) {
 		// looks like regexp to me
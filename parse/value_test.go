@@ -51,6 +51,16 @@ func TestValueEqual(t *testing.T) {
 	is.Equal("regex", v.Type())
 	is.Equal(`/application/json/`, v.String())
 
+	v = ParseValue([]byte(`["a", 1]`))
+	is.True(v.Equal([]interface{}{"a", 1.0}))
+	is.False(v.Equal([]interface{}{"a"}))
+	is.False(v.Equal("a"))
+
+	v = ParseValue([]byte(`"/"`))
+	is.True(v.Equal("/"))
+	is.False(v.Equal("/path"))
+	is.Equal("string", v.Type())
+
 	v = ParseValue([]byte("/Silk/"))
 	is.True(v.Equal("My name is Silk."))
 	is.True(v.Equal("Silk is my name."))
@@ -0,0 +1,3 @@
+// Package query provides JSONPath style queries for finding
+// values in decoded response data.
+package query
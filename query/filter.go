@@ -0,0 +1,239 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// expr is a filter expression evaluated against
+// each candidate (@) value.
+type expr interface {
+	eval(current interface{}) interface{}
+}
+
+// missing is the value of a path that matched nothing.
+type missing struct{}
+
+type literal struct {
+	value interface{}
+}
+
+func (l literal) eval(interface{}) interface{} {
+	return l.value
+}
+
+// current is a path relative to @.
+type current struct {
+	steps []step
+}
+
+func (c current) eval(v interface{}) interface{} {
+	matches := find(c.steps, v)
+	if len(matches) == 0 {
+		return missing{}
+	}
+	if definite(c.steps) {
+		return matches[0]
+	}
+	return matches
+}
+
+type binary struct {
+	op          string
+	left, right expr
+	re          *regexp.Regexp
+}
+
+func (b binary) eval(v interface{}) interface{} {
+	switch b.op {
+	case "&&":
+		return truthy(b.left.eval(v)) && truthy(b.right.eval(v))
+	case "||":
+		return truthy(b.left.eval(v)) || truthy(b.right.eval(v))
+	}
+	left := b.left.eval(v)
+	if _, ok := left.(missing); ok {
+		return b.op == "!="
+	}
+	if b.op == "=~" {
+		return b.re.MatchString(fmt.Sprintf("%v", left))
+	}
+	right := b.right.eval(v)
+	switch b.op {
+	case "==":
+		return reflect.DeepEqual(left, right)
+	case "!=":
+		return !reflect.DeepEqual(left, right)
+	}
+	if l, ok := left.(float64); ok {
+		if r, ok := right.(float64); ok {
+			return compare(b.op, l < r, l == r)
+		}
+	}
+	if l, ok := left.(string); ok {
+		if r, ok := right.(string); ok {
+			return compare(b.op, l < r, l == r)
+		}
+	}
+	return false
+}
+
+func compare(op string, less, equal bool) bool {
+	switch op {
+	case "<":
+		return less
+	case "<=":
+		return less || equal
+	case ">":
+		return !less && !equal
+	case ">=":
+		return !less
+	}
+	return false
+}
+
+// truthy gets whether a filter result selects a value.
+// Missing values, nil and false do not.
+func truthy(v interface{}) bool {
+	switch val := v.(type) {
+	case missing, nil:
+		return false
+	case bool:
+		return val
+	}
+	return true
+}
+
+// orExpr parses a || b
+func (p *parser) orExpr() (expr, error) {
+	left, err := p.andExpr()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpace()
+		if !p.peek("||") {
+			return left, nil
+		}
+		p.pos += 2
+		right, err := p.andExpr()
+		if err != nil {
+			return nil, err
+		}
+		left = binary{op: "||", left: left, right: right}
+	}
+}
+
+// andExpr parses a && b
+func (p *parser) andExpr() (expr, error) {
+	left, err := p.comparison()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpace()
+		if !p.peek("&&") {
+			return left, nil
+		}
+		p.pos += 2
+		right, err := p.comparison()
+		if err != nil {
+			return nil, err
+		}
+		left = binary{op: "&&", left: left, right: right}
+	}
+}
+
+var operators = []string{"==", "!=", "=~", "<=", ">=", "<", ">"}
+
+// comparison parses a single operand, or two operands
+// with an operator.
+func (p *parser) comparison() (expr, error) {
+	left, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	for _, op := range operators {
+		if !p.peek(op) {
+			continue
+		}
+		p.pos += len(op)
+		p.skipSpace()
+		if op == "=~" {
+			re, err := p.regex()
+			if err != nil {
+				return nil, err
+			}
+			return binary{op: op, left: left, re: re}, nil
+		}
+		right, err := p.operand()
+		if err != nil {
+			return nil, err
+		}
+		return binary{op: op, left: left, right: right}, nil
+	}
+	return left, nil
+}
+
+// operand parses @path or a literal value.
+func (p *parser) operand() (expr, error) {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return nil, p.errorf("expected value")
+	}
+	switch c := p.src[p.pos]; {
+	case c == '@':
+		p.pos++
+		inFilter := p.filter
+		p.filter = true
+		defer func() { p.filter = inFilter }()
+		if p.pos >= len(p.src) || (p.src[p.pos] != '.' && p.src[p.pos] != '[') {
+			// @ on its own is the current value
+			return current{}, nil
+		}
+		steps, err := p.steps(false)
+		if err != nil {
+			return nil, err
+		}
+		return current{steps: steps}, nil
+	case c == '\'' || c == '"':
+		s, err := p.quoted()
+		if err != nil {
+			return nil, err
+		}
+		return literal{value: s}, nil
+	}
+	start := p.pos
+	for p.pos < len(p.src) && strings.IndexByte(" =!<>&|)]", p.src[p.pos]) == -1 {
+		p.pos++
+	}
+	var v interface{}
+	if err := json.Unmarshal([]byte(p.src[start:p.pos]), &v); err != nil {
+		src := p.src[start:p.pos]
+		p.pos = start
+		return nil, p.errorf("invalid value %q", src)
+	}
+	return literal{value: v}, nil
+}
+
+// regex parses /pattern/
+func (p *parser) regex() (*regexp.Regexp, error) {
+	if !p.peek("/") {
+		return nil, p.errorf("expected /regex/")
+	}
+	end := strings.IndexByte(p.src[p.pos+1:], '/')
+	if end == -1 {
+		return nil, p.errorf("unterminated regex")
+	}
+	pattern := p.src[p.pos+1 : p.pos+1+end]
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, p.errorf("invalid regex: %s", err)
+	}
+	p.pos += end + 2
+	return re, nil
+}
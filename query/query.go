@@ -0,0 +1,393 @@
+package query
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Error describes a malformed query.
+type Error struct {
+	Query string
+	Pos   int
+	Msg   string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("malformed path %q: %s at position %d", e.Query, e.Msg, e.Pos)
+}
+
+type stepKind int8
+
+const (
+	stepName stepKind = iota
+	stepIndex
+	stepWildcard
+	stepSlice
+	stepFilter
+)
+
+// step is a single part of a Query.
+type step struct {
+	kind      stepKind
+	recursive bool
+	name      string
+	index     int
+	start     *int
+	end       *int
+	filter    expr
+}
+
+// Query is a compiled path into decoded data.
+type Query struct {
+	src   string
+	steps []step
+}
+
+// Parse compiles a query, like:
+//
+//	Data.items[0].name
+//	Data.items[*].price
+//	Data.items[?(@.id==3)].name
+//	Data..name
+func Parse(src string) (*Query, error) {
+	p := &parser{src: src}
+	steps, err := p.steps(true)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(src) {
+		return nil, p.errorf("unexpected %q", src[p.pos])
+	}
+	return &Query{src: src, steps: steps}, nil
+}
+
+func (q *Query) String() string {
+	return q.src
+}
+
+// Definite gets whether the query can match at most one value.
+// Queries with wildcards, slices, filters or recursive descent
+// are not definite.
+func (q *Query) Definite() bool {
+	return definite(q.steps)
+}
+
+func definite(steps []step) bool {
+	for _, s := range steps {
+		if s.recursive || (s.kind != stepName && s.kind != stepIndex) {
+			return false
+		}
+	}
+	return true
+}
+
+// Find gets all values in data matched by the query.
+// Maps must be of type map[string]interface{} and slices
+// of type []interface{}, as produced by encoding/json.
+func (q *Query) Find(data interface{}) []interface{} {
+	return find(q.steps, data)
+}
+
+func find(steps []step, data interface{}) []interface{} {
+	nodes := []interface{}{data}
+	for _, s := range steps {
+		var next []interface{}
+		for _, node := range nodes {
+			candidates := []interface{}{node}
+			if s.recursive {
+				candidates = descendants(node, nil)
+			}
+			for _, candidate := range candidates {
+				next = append(next, s.apply(candidate)...)
+			}
+		}
+		nodes = next
+		if len(nodes) == 0 {
+			break
+		}
+	}
+	return nodes
+}
+
+// apply gets the values selected by the step from node.
+func (s step) apply(node interface{}) []interface{} {
+	switch s.kind {
+	case stepName:
+		if m, ok := node.(map[string]interface{}); ok {
+			if v, ok := m[s.name]; ok {
+				return []interface{}{v}
+			}
+		}
+	case stepIndex:
+		if list, ok := node.([]interface{}); ok {
+			i := s.index
+			if i < 0 {
+				i += len(list)
+			}
+			if i >= 0 && i < len(list) {
+				return []interface{}{list[i]}
+			}
+		}
+	case stepWildcard:
+		return children(node)
+	case stepSlice:
+		if list, ok := node.([]interface{}); ok {
+			start, end := 0, len(list)
+			if s.start != nil {
+				start = clamp(*s.start, len(list))
+			}
+			if s.end != nil {
+				end = clamp(*s.end, len(list))
+			}
+			if start < end {
+				return list[start:end]
+			}
+		}
+	case stepFilter:
+		var matches []interface{}
+		for _, child := range children(node) {
+			if truthy(s.filter.eval(child)) {
+				matches = append(matches, child)
+			}
+		}
+		return matches
+	}
+	return nil
+}
+
+func clamp(i, length int) int {
+	if i < 0 {
+		i += length
+	}
+	if i < 0 {
+		return 0
+	}
+	if i > length {
+		return length
+	}
+	return i
+}
+
+// children gets the values of a map (ordered by key)
+// or the items of a slice.
+func children(node interface{}) []interface{} {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(n))
+		for k := range n {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		values := make([]interface{}, len(keys))
+		for i, k := range keys {
+			values[i] = n[k]
+		}
+		return values
+	case []interface{}:
+		return n
+	}
+	return nil
+}
+
+// descendants gets the node and all nodes beneath it.
+func descendants(node interface{}, nodes []interface{}) []interface{} {
+	nodes = append(nodes, node)
+	for _, child := range children(node) {
+		nodes = descendants(child, nodes)
+	}
+	return nodes
+}
+
+type parser struct {
+	src string
+	pos int
+	// filter is true while parsing a filter expression,
+	// where names end at spaces and operators.
+	filter bool
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return &Error{Query: p.src, Pos: p.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) peek(s string) bool {
+	return strings.HasPrefix(p.src[p.pos:], s)
+}
+
+// steps parses steps until the end of the source or a character
+// that cannot continue a path.
+// If root is true, the path may begin with a name.
+func (p *parser) steps(root bool) ([]step, error) {
+	var steps []step
+	if root && p.pos < len(p.src) && p.src[p.pos] != '.' && p.src[p.pos] != '[' {
+		name := p.name()
+		if len(name) == 0 {
+			return nil, p.errorf("expected name")
+		}
+		steps = append(steps, step{kind: stepName, name: name})
+	}
+	for p.pos < len(p.src) {
+		switch {
+		case p.peek(".."):
+			p.pos += 2
+			s, err := p.dotOrBracket()
+			if err != nil {
+				return nil, err
+			}
+			s.recursive = true
+			steps = append(steps, s)
+		case p.peek("."):
+			p.pos++
+			s, err := p.dotOrBracket()
+			if err != nil {
+				return nil, err
+			}
+			steps = append(steps, s)
+		case p.peek("["):
+			s, err := p.bracket()
+			if err != nil {
+				return nil, err
+			}
+			steps = append(steps, s)
+		default:
+			return steps, nil
+		}
+	}
+	if len(steps) == 0 {
+		return nil, p.errorf("empty path")
+	}
+	return steps, nil
+}
+
+// dotOrBracket parses what follows a . or ..
+func (p *parser) dotOrBracket() (step, error) {
+	if p.peek("[") {
+		return p.bracket()
+	}
+	if p.peek("*") {
+		p.pos++
+		return step{kind: stepWildcard}, nil
+	}
+	name := p.name()
+	if len(name) == 0 {
+		return step{}, p.errorf("expected name")
+	}
+	return step{kind: stepName, name: name}, nil
+}
+
+// name reads a key up to the next . or [ (or the end of a filter
+// operand).
+func (p *parser) name() string {
+	start := p.pos
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		if c == '.' || c == '[' {
+			break
+		}
+		if p.filter && strings.IndexByte(" =!<>&|)]", c) > -1 {
+			break
+		}
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+// bracket parses [n], [a:b], [*], ['name'] and [?(filter)].
+func (p *parser) bracket() (step, error) {
+	p.pos++ // [
+	var s step
+	switch {
+	case p.peek("*]"):
+		p.pos += 2
+		return step{kind: stepWildcard}, nil
+	case p.peek("?("):
+		p.pos += 2
+		filter, err := p.orExpr()
+		if err != nil {
+			return s, err
+		}
+		p.skipSpace()
+		if !p.peek(")]") {
+			return s, p.errorf(`expected ")]"`)
+		}
+		p.pos += 2
+		return step{kind: stepFilter, filter: filter}, nil
+	case p.peek("'") || p.peek(`"`):
+		name, err := p.quoted()
+		if err != nil {
+			return s, err
+		}
+		if !p.peek("]") {
+			return s, p.errorf(`expected "]"`)
+		}
+		p.pos++
+		return step{kind: stepName, name: name}, nil
+	}
+	end := strings.IndexByte(p.src[p.pos:], ']')
+	if end == -1 {
+		return s, p.errorf(`missing "]"`)
+	}
+	inner := p.src[p.pos : p.pos+end]
+	if colon := strings.IndexByte(inner, ':'); colon > -1 {
+		s.kind = stepSlice
+		var err error
+		if s.start, err = p.optionalInt(strings.TrimSpace(inner[:colon])); err != nil {
+			return s, err
+		}
+		if s.end, err = p.optionalInt(strings.TrimSpace(inner[colon+1:])); err != nil {
+			return s, err
+		}
+	} else {
+		i, err := strconv.Atoi(strings.TrimSpace(inner))
+		if err != nil {
+			return s, p.errorf("invalid index %q", inner)
+		}
+		s.kind, s.index = stepIndex, i
+	}
+	p.pos += end + 1
+	return s, nil
+}
+
+func (p *parser) optionalInt(s string) (*int, error) {
+	if len(s) == 0 {
+		return nil, nil
+	}
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		return nil, p.errorf("invalid slice index %q", s)
+	}
+	return &i, nil
+}
+
+// quoted reads a 'single' or "double" quoted string.
+func (p *parser) quoted() (string, error) {
+	quote := p.src[p.pos]
+	start := p.pos
+	p.pos++
+	var b bytes.Buffer
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case c == '\\' && p.pos+1 < len(p.src):
+			b.WriteByte(p.src[p.pos+1])
+			p.pos += 2
+			continue
+		case c == quote:
+			p.pos++
+			return b.String(), nil
+		}
+		b.WriteByte(c)
+		p.pos++
+	}
+	p.pos = start
+	return "", p.errorf("unterminated string")
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.src) && p.src[p.pos] == ' ' {
+		p.pos++
+	}
+}
@@ -0,0 +1,131 @@
+package query_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/cheekybits/is"
+	"github.com/matryer/silk/query"
+)
+
+const testData = `{
+	"Data": {
+		"name": "Silk",
+		"Content-Type": "application/json",
+		"tags": ["testing", "markdown"],
+		"items": [
+			{"id": 1, "name": "Book", "price": 10, "tags": ["paper"]},
+			{"id": 2, "name": "Pen", "price": 2.5},
+			{"id": 3, "name": "Lamp", "price": 30, "owner": {"name": "Mat"}}
+		]
+	}
+}`
+
+func TestQueryFind(t *testing.T) {
+	is := is.New(t)
+	var data interface{}
+	is.NoErr(json.Unmarshal([]byte(testData), &data))
+
+	var tests = []struct {
+		Path     string
+		Definite bool
+		Expected []interface{}
+	}{{
+		Path:     "Data.name",
+		Definite: true,
+		Expected: []interface{}{"Silk"},
+	}, {
+		Path:     "Data.Content-Type",
+		Definite: true,
+		Expected: []interface{}{"application/json"},
+	}, {
+		Path:     "Data.tags[1]",
+		Definite: true,
+		Expected: []interface{}{"markdown"},
+	}, {
+		Path:     "Data.tags[-1]",
+		Definite: true,
+		Expected: []interface{}{"markdown"},
+	}, {
+		Path:     "Data['name']",
+		Definite: true,
+		Expected: []interface{}{"Silk"},
+	}, {
+		Path:     "Data.missing",
+		Definite: true,
+		Expected: nil,
+	}, {
+		Path:     "Data.items[*].price",
+		Expected: []interface{}{10.0, 2.5, 30.0},
+	}, {
+		Path:     "Data.items.*.id",
+		Expected: []interface{}{1.0, 2.0, 3.0},
+	}, {
+		Path:     "Data.items[0:2].name",
+		Expected: []interface{}{"Book", "Pen"},
+	}, {
+		Path:     "Data.items[-1:].name",
+		Expected: []interface{}{"Lamp"},
+	}, {
+		Path:     "Data.items[?(@.id==3)].name",
+		Expected: []interface{}{"Lamp"},
+	}, {
+		Path:     "Data.items[?(@.price < 20 && @.id != 1)].name",
+		Expected: []interface{}{"Pen"},
+	}, {
+		Path:     "Data.items[?(@.id == 1 || @.name == 'Lamp')].id",
+		Expected: []interface{}{1.0, 3.0},
+	}, {
+		Path:     "Data.items[?(@.owner)].id",
+		Expected: []interface{}{3.0},
+	}, {
+		Path:     "Data.items[?(@.name =~ /^[BP]/)].id",
+		Expected: []interface{}{1.0, 2.0},
+	}, {
+		Path:     "Data.items[?(@.tags[0] == \"paper\")].id",
+		Expected: []interface{}{1.0},
+	}, {
+		Path:     "Data.tags[?(@ == 'testing')]",
+		Expected: []interface{}{"testing"},
+	}, {
+		Path:     "Data..name",
+		Expected: []interface{}{"Silk", "Book", "Pen", "Lamp", "Mat"},
+	}, {
+		Path:     "Data..owner.name",
+		Expected: []interface{}{"Mat"},
+	}}
+	for _, test := range tests {
+		q, err := query.Parse(test.Path)
+		is.NoErr(err)
+		is.Equal(q.String(), test.Path)
+		is.Equal(q.Definite(), test.Definite)
+		if actual := q.Find(data); !reflect.DeepEqual(actual, test.Expected) {
+			t.Errorf("%s: expected %v but got %v", test.Path, test.Expected, actual)
+		}
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	is := is.New(t)
+	for _, path := range []string{
+		"",
+		"Data.",
+		"Data[",
+		"Data[abc]",
+		"Data[1:x]",
+		"Data['name",
+		"Data.items[?(@.id==3].name",
+		"Data.items[?(@.id==)]",
+		"Data.items[?(@.id=~abc)]",
+		"Data.items[?(@.id=~/[/)]",
+		"Data.items[?(@.id==nope)]",
+	} {
+		_, err := query.Parse(path)
+		is.Err(err)
+		_, ok := err.(*query.Error)
+		is.True(ok)
+	}
+	_, err := query.Parse("Data.items[?(@.id==3].name")
+	is.Equal(err.Error(), `malformed path "Data.items[?(@.id==3].name": expected ")]" at position 20`)
+}
@@ -0,0 +1,4 @@
+// Package report writes the results of running silk documents
+// in formats for other tools, like annotated Markdown for pull
+// request comments.
+package report
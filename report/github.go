@@ -0,0 +1,100 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/matryer/silk/parse"
+	"github.com/matryer/silk/runner"
+)
+
+// GitHub writes a GitHub Actions workflow command for every failed
+// request, so that failures are annotated on the line of the
+// document that failed:
+//
+//	::error file=users.silk.md,line=19,title=GET /users/2::Data.email doesn't match
+func GitHub(w io.Writer, groups []*parse.Group, results []*runner.Result) error {
+	bw := bufio.NewWriter(w)
+	for _, group := range groups {
+		for _, req := range group.Requests {
+			res := runner.FindResult(results, group.Filename, req.Line)
+			if res == nil || res.Passed || res.Failure == nil {
+				continue
+			}
+			f := res.Failure
+			message := f.Message
+			if len(f.Key) > 0 {
+				message += "\nexpected: " + f.Expected + "\nactual: " + f.Actual
+			}
+			fmt.Fprintf(bw, "::error file=%s,line=%d,title=%s::%s\n",
+				escapeProperty(filepath.ToSlash(filepath.Clean(group.Filename))),
+				f.Line,
+				escapeProperty(string(req.Method)+" "+string(req.Path)),
+				escapeData(message))
+		}
+	}
+	return bw.Flush()
+}
+
+// GitHubSummary writes a Markdown table of every request in groups
+// and its result, for $GITHUB_STEP_SUMMARY.
+func GitHubSummary(w io.Writer, groups []*parse.Group, results []*runner.Result) error {
+	bw := bufio.NewWriter(w)
+	var rows []string
+	var passed, failed, notRun int
+	for _, group := range groups {
+		for _, req := range group.Requests {
+			res := runner.FindResult(results, group.Filename, req.Line)
+			mark, location, message := markNotRun, fmt.Sprintf("%s:%d", group.Filename, req.Line), ""
+			switch {
+			case res == nil:
+				notRun++
+			case res.Passed:
+				passed++
+				mark = markPassed
+			default:
+				failed++
+				mark = markFailed
+				if res.Failure != nil {
+					location = fmt.Sprintf("%s:%d", group.Filename, res.Failure.Line)
+					message = res.Failure.Message
+				}
+			}
+			rows = append(rows, fmt.Sprintf("| %s | %s | %s | %s |", mark,
+				cell(code(string(req.Method)+" "+string(req.Path))), cell(location), cell(message)))
+		}
+	}
+	fmt.Fprintln(bw, "### silk")
+	fmt.Fprintln(bw)
+	fmt.Fprintf(bw, "%d passed, %d failed, %d not run\n\n", passed, failed, notRun)
+	fmt.Fprintln(bw, "| | Request | Location | Failure |")
+	fmt.Fprintln(bw, "|---|---|---|---|")
+	for _, row := range rows {
+		fmt.Fprintln(bw, row)
+	}
+	return bw.Flush()
+}
+
+// cell escapes s for a Markdown table cell.
+func cell(s string) string {
+	s = strings.Replace(s, "|", `\|`, -1)
+	return strings.Replace(s, "\n", " ", -1)
+}
+
+var (
+	dataEscaper     = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	propertyEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+)
+
+// escapeData escapes the message of a workflow command.
+func escapeData(s string) string {
+	return dataEscaper.Replace(s)
+}
+
+// escapeProperty escapes a property of a workflow command.
+func escapeProperty(s string) string {
+	return propertyEscaper.Replace(s)
+}
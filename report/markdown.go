@@ -0,0 +1,177 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/matryer/silk/parse"
+	"github.com/matryer/silk/runner"
+)
+
+// Marks for the status of a request.
+const (
+	markPassed = "✅"
+	markFailed = "❌"
+	markNotRun = "➖"
+)
+
+// Markdown writes a report of a document with the same structure
+// as the document: each request heading is marked as passed, failed
+// or not run, and failing assertions show the expected and actual
+// values inline.
+// The groups should all be from the same document.
+func Markdown(w io.Writer, groups []*parse.Group, results []*runner.Result) error {
+	bw := bufio.NewWriter(w)
+	var passed, failed, notRun int
+	for _, group := range groups {
+		for _, req := range group.Requests {
+			switch res := runner.FindResult(results, group.Filename, req.Line); {
+			case res == nil:
+				notRun++
+			case res.Passed:
+				passed++
+			default:
+				failed++
+			}
+		}
+	}
+	fmt.Fprintf(bw, "> silk: %d passed, %d failed, %d not run\n\n", passed, failed, notRun)
+	for _, group := range groups {
+		fmt.Fprintf(bw, "# %s\n", group.Title)
+		writeItems(bw, nil, collect(group.Description, group.Details))
+		for _, req := range group.Requests {
+			writeRequest(bw, group.Filename, req, runner.FindResult(results, group.Filename, req.Line))
+		}
+	}
+	return bw.Flush()
+}
+
+func writeRequest(w io.Writer, filename string, req *parse.Request, res *runner.Result) {
+	var failure *runner.Failure
+	switch {
+	case res == nil:
+		fmt.Fprintf(w, "## %s %s %s not run\n", req.Method, req.Path, markNotRun)
+	case res.Passed:
+		fmt.Fprintf(w, "## %s %s %s passed\n", req.Method, req.Path, markPassed)
+	default:
+		failure = res.Failure
+		fmt.Fprintf(w, "## %s %s %s failed\n", req.Method, req.Path, markFailed)
+		fmt.Fprintf(w, "\n> %s **%s:%d** %s\n", markFailed, filepath.Base(filename), failure.Line, failure.Message)
+	}
+	items := collect(req.Description, req.Params, req.Details, req.Parts, req.Form)
+	items = append(items, codeblock(filename, req.Body, req.BodyType, req.BodyFile)...)
+	writeItems(w, failure, items)
+	fmt.Fprintln(w, "===")
+	items = collect(req.ExpectedDescription, req.ExpectedDetails)
+	items = append(items, codeblock(filename, req.ExpectedBody, req.ExpectedBodyType, req.ExpectedBodyFile)...)
+	writeItems(w, failure, items)
+}
+
+// item is a line, or a code block, of a document.
+type item struct {
+	// first and last are the line numbers of the item.
+	first, last int
+	lines       []string
+	// body is true for code blocks and body files.
+	body bool
+}
+
+// collect makes items of lines.
+func collect(lists ...parse.Lines) []item {
+	var items []item
+	for _, lines := range lists {
+		for _, line := range lines {
+			text := string(line.Bytes)
+			if len(line.Comment) > 0 {
+				text += " //" + string(line.Comment)
+			}
+			items = append(items, item{first: line.Number, last: line.Number, lines: []string{text}})
+		}
+	}
+	return items
+}
+
+// codeblock makes the item for a body, if there is one.
+func codeblock(filename string, body parse.Lines, bodyType string, file *parse.File) []item {
+	if file != nil {
+		ref := file.Path
+		if rel, err := filepath.Rel(filepath.Dir(filename), file.Path); err == nil {
+			ref = rel
+		}
+		return []item{{first: file.Line, last: file.Line, lines: []string{"* Body: @" + filepath.ToSlash(ref)}, body: true}}
+	}
+	if len(body) == 0 {
+		return nil
+	}
+	lines := []string{"```" + bodyType}
+	for _, line := range body {
+		lines = append(lines, string(line.Bytes))
+	}
+	lines = append(lines, "```")
+	// the fences are before and after the body
+	return []item{{first: body.Number() - 1, last: body[len(body)-1].Number + 1, lines: lines, body: true}}
+}
+
+// writeItems writes the items in the order they appear in the
+// document, followed by the failure if it is at one of them.
+func writeItems(w io.Writer, failure *runner.Failure, all []item) {
+	sort.SliceStable(all, func(i, j int) bool { return all[i].first < all[j].first })
+	for _, it := range all {
+		for _, line := range it.lines {
+			fmt.Fprintln(w, line)
+		}
+		if failure != nil && failure.Line >= it.first && failure.Line <= it.last {
+			writeFailure(w, failure, it.body)
+		}
+	}
+}
+
+// writeFailure writes the expected and actual values of a failure
+// after the line or body that failed.
+func writeFailure(w io.Writer, failure *runner.Failure, body bool) {
+	switch {
+	case body && failure.Key == "Body":
+		fmt.Fprintf(w, "\n%s actual body:\n\n", markFailed)
+		fence := fence(failure.Actual)
+		fmt.Fprintln(w, fence)
+		fmt.Fprintln(w, strings.TrimRight(failure.Actual, "\n"))
+		fmt.Fprintln(w, fence)
+	case body:
+		fmt.Fprintf(w, "\n%s %s\n", markFailed, failure.Message)
+	case len(failure.Key) > 0:
+		fmt.Fprintf(w, "  * %s expected %s actual %s\n", markFailed, code(failure.Expected), code(failure.Actual))
+	default:
+		fmt.Fprintf(w, "  * %s %s\n", markFailed, failure.Message)
+	}
+}
+
+// code formats s as inline code.
+func code(s string) string {
+	if strings.Contains(s, "`") {
+		return "`` " + s + " ``"
+	}
+	return "`" + s + "`"
+}
+
+// fence gets a code fence longer than any run of back tics in s.
+func fence(s string) string {
+	longest, run := 0, 0
+	for _, c := range s {
+		if c == '`' {
+			run++
+			if run > longest {
+				longest = run
+			}
+			continue
+		}
+		run = 0
+	}
+	if longest < 3 {
+		return "```"
+	}
+	return strings.Repeat("`", longest+1)
+}
@@ -0,0 +1,180 @@
+package report_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cheekybits/is"
+	"github.com/matryer/silk/parse"
+	"github.com/matryer/silk/report"
+	"github.com/matryer/silk/runner"
+)
+
+// results are the results of running users.silk.md.
+var results = []*runner.Result{{
+	Filename: "../testfiles/success/users.silk.md",
+	Group:    "Users",
+	Line:     5,
+	Method:   "GET",
+	Path:     "/users",
+	Passed:   true,
+}, {
+	Filename: "../testfiles/success/users.silk.md",
+	Group:    "Users",
+	Line:     14,
+	Method:   "GET",
+	Path:     "/users/2",
+	Failure: &runner.Failure{
+		Line:     19,
+		Message:  "Data.email doesn't match",
+		Key:      "Data.email",
+		Expected: `"david@example.com"`,
+		Actual:   `"dave@example.com"`,
+	},
+}}
+
+func TestMarkdown(t *testing.T) {
+	is := is.New(t)
+	groups, err := parse.ParseFile("../testfiles/success/users.silk.md")
+	is.NoErr(err)
+	var buf bytes.Buffer
+	is.NoErr(report.Markdown(&buf, groups, results))
+	is.Equal(buf.String(), "> silk: 1 passed, 1 failed, 3 not run\n"+`
+# Users
+
+Every request and response here matches `+"`openapi.yaml`"+`.
+
+## GET /users ✅ passed
+
+* ?limit=1
+
+===
+
+* Status: 200
+* Data[0].name: "Mat"
+
+## GET /users/2 ❌ failed
+
+> ❌ **users.silk.md:19** Data.email doesn't match
+
+===
+
+* Status: 200
+* Data.email: "david@example.com"
+  * ❌ expected `+"`\"david@example.com\"`"+` actual `+"`\"dave@example.com\"`"+`
+
+## GET /users/3 ➖ not run
+
+===
+
+* Status: 404
+* Data.error: "not found"
+
+## POST /users ➖ not run
+
+* Content-Type: "application/json"
+
+`+"```json"+`
+{"name": "Silk", "email": "silk@example.com"}
+`+"```"+`
+
+===
+
+* Status: 201
+* Location: "/users/3"
+* Data.id: 3
+
+## DELETE /users/1 ➖ not run
+
+===
+
+* Status: 204
+`)
+}
+
+func TestMarkdownBody(t *testing.T) {
+	is := is.New(t)
+	groups, err := parse.ParseFile("../testfiles/failure/echo.failure.wrongbody.silk.md")
+	is.NoErr(err)
+	results := []*runner.Result{{
+		Filename: "../testfiles/failure/echo.failure.wrongbody.silk.md",
+		Line:     groups[0].Requests[0].Line,
+		Failure: &runner.Failure{
+			Line:     groups[0].Requests[0].ExpectedBody.Number(),
+			Message:  "body doesn't match",
+			Key:      "Body",
+			Expected: "Hello silk.",
+			Actual:   "```\nGET /echo\n```",
+		},
+	}}
+	var buf bytes.Buffer
+	is.NoErr(report.Markdown(&buf, groups, results))
+	is.True(bytes.Contains(buf.Bytes(), []byte("```\n\n❌ actual body:\n\n````\n```\nGET /echo\n```\n````\n")))
+}
+
+func TestTAP(t *testing.T) {
+	is := is.New(t)
+	groups, err := parse.ParseFile("../testfiles/success/users.silk.md")
+	is.NoErr(err)
+	var buf bytes.Buffer
+	is.NoErr(report.TAP(&buf, groups, results))
+	is.Equal(buf.String(), `TAP version 13
+ok 1 - GET /users (../testfiles/success/users.silk.md:5)
+not ok 2 - GET /users/2 (../testfiles/success/users.silk.md:14)
+  ---
+  method: GET
+  path: /users/2
+  at: ../testfiles/success/users.silk.md:19
+  message: Data.email doesn't match
+  key: Data.email
+  expected: '"david@example.com"'
+  actual: '"dave@example.com"'
+  ...
+ok 3 - GET /users/3 (../testfiles/success/users.silk.md:21) # SKIP not run
+ok 4 - POST /users (../testfiles/success/users.silk.md:28) # SKIP not run
+ok 5 - DELETE /users/1 (../testfiles/success/users.silk.md:42) # SKIP not run
+1..5
+`)
+}
+
+func TestTAPEscape(t *testing.T) {
+	is := is.New(t)
+	groups, err := parse.Parse("escape.silk.md", bytes.NewReader([]byte("# Escape\n## GET /users#top\n===\n* Status: 200\n")))
+	is.NoErr(err)
+	var buf bytes.Buffer
+	is.NoErr(report.TAP(&buf, groups, nil))
+	is.Equal(buf.String(), `TAP version 13
+ok 1 - GET /users\#top (escape.silk.md:2) # SKIP not run
+1..1
+`)
+}
+
+func TestGitHub(t *testing.T) {
+	is := is.New(t)
+	groups, err := parse.ParseFile("../testfiles/success/users.silk.md")
+	is.NoErr(err)
+	var buf bytes.Buffer
+	is.NoErr(report.GitHub(&buf, groups, results))
+	is.Equal(buf.String(), "::error file=../testfiles/success/users.silk.md,line=19,title=GET /users/2::"+
+		`Data.email doesn't match%0Aexpected: "david@example.com"%0Aactual: "dave@example.com"`+"\n")
+}
+
+func TestGitHubSummary(t *testing.T) {
+	is := is.New(t)
+	groups, err := parse.ParseFile("../testfiles/success/users.silk.md")
+	is.NoErr(err)
+	var buf bytes.Buffer
+	is.NoErr(report.GitHubSummary(&buf, groups, results))
+	is.Equal(buf.String(), `### silk
+
+1 passed, 1 failed, 3 not run
+
+| | Request | Location | Failure |
+|---|---|---|---|
+| ✅ | `+"`GET /users`"+` | ../testfiles/success/users.silk.md:5 |  |
+| ❌ | `+"`GET /users/2`"+` | ../testfiles/success/users.silk.md:19 | Data.email doesn't match |
+| ➖ | `+"`GET /users/3`"+` | ../testfiles/success/users.silk.md:21 |  |
+| ➖ | `+"`POST /users`"+` | ../testfiles/success/users.silk.md:28 |  |
+| ➖ | `+"`DELETE /users/1`"+` | ../testfiles/success/users.silk.md:42 |  |
+`)
+}
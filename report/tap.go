@@ -0,0 +1,87 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/matryer/silk/parse"
+	"github.com/matryer/silk/runner"
+	"gopkg.in/yaml.v2"
+)
+
+// diagnostic is the YAML diagnostic block of a failed request.
+type diagnostic struct {
+	Method   string `yaml:"method"`
+	Path     string `yaml:"path"`
+	At       string `yaml:"at"`
+	Message  string `yaml:"message"`
+	Key      string `yaml:"key,omitempty"`
+	Expected string `yaml:"expected,omitempty"`
+	Actual   string `yaml:"actual,omitempty"`
+}
+
+// TAP writes a line for every request in groups in the Test
+// Anything Protocol (version 13), like:
+//
+//	ok 1 - GET /users (users.silk.md:5)
+//
+// Failed requests have a YAML diagnostic block with the method,
+// path, file:line and the expected and actual values of the failing
+// assertion. Requests that weren't run are skipped.
+func TAP(w io.Writer, groups []*parse.Group, results []*runner.Result) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "TAP version 13")
+	n := 0
+	for _, group := range groups {
+		for _, req := range group.Requests {
+			n++
+			res := runner.FindResult(results, group.Filename, req.Line)
+			description := tapEscaper.Replace(fmt.Sprintf("%s %s (%s:%d)", req.Method, req.Path, group.Filename, req.Line))
+			switch {
+			case res == nil:
+				fmt.Fprintf(bw, "ok %d - %s # SKIP not run\n", n, description)
+			case res.Passed:
+				fmt.Fprintf(bw, "ok %d - %s\n", n, description)
+			default:
+				fmt.Fprintf(bw, "not ok %d - %s\n", n, description)
+				if err := writeDiagnostic(bw, res); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	fmt.Fprintf(bw, "1..%d\n", n)
+	return bw.Flush()
+}
+
+// tapEscaper escapes descriptions, so a # isn't read as the
+// start of a directive.
+var tapEscaper = strings.NewReplacer(`\`, `\\`, "#", `\#`)
+
+func writeDiagnostic(w io.Writer, res *runner.Result) error {
+	d := diagnostic{
+		Method: res.Method,
+		Path:   res.Path,
+		At:     res.Filename + ":" + strconv.Itoa(res.Line),
+	}
+	if f := res.Failure; f != nil {
+		d.At = res.Filename + ":" + strconv.Itoa(f.Line)
+		d.Message = f.Message
+		d.Key = f.Key
+		d.Expected = f.Expected
+		d.Actual = f.Actual
+	}
+	b, err := yaml.Marshal(d)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w, "  ---")
+	for _, line := range strings.Split(strings.TrimRight(string(b), "\n"), "\n") {
+		fmt.Fprintln(w, "  "+line)
+	}
+	fmt.Fprintln(w, "  ...")
+	return nil
+}
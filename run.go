@@ -0,0 +1,260 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/matryer/silk/coverage"
+	"github.com/matryer/silk/openapi"
+	"github.com/matryer/silk/parse"
+	"github.com/matryer/silk/report"
+	"github.com/matryer/silk/runner"
+)
+
+// silkT is the runner.T that failures are reported to.
+// Like testing.T, FailNow stops the goroutine running the
+// current file.
+type silkT struct {
+	out    io.Writer
+	failed bool
+}
+
+func (t *silkT) FailNow() {
+	t.failed = true
+	runtime.Goexit()
+}
+
+func (t *silkT) Log(args ...interface{}) {
+	fmt.Fprintln(t.out, args...)
+}
+
+// runFiles runs the files, and gets the exit code.
+func runFiles(opts *options, paths []string, stdout, stderr io.Writer) int {
+	if opts.url == "" {
+		fmt.Fprintln(stderr, "silk: -silk.url is required")
+		return exitError
+	}
+	if len(paths) == 0 {
+		fmt.Fprintln(stderr, "silk: no files to run")
+		return exitError
+	}
+	format, ok := reportFormats[opts.reportName]
+	if opts.reportName != "" && !ok {
+		fmt.Fprintln(stderr, "silk: unknown -silk.report format", opts.reportName)
+		return exitError
+	}
+	// parse every file first, so mistakes are found before
+	// any requests are made
+	files := make([][]*parse.Group, len(paths))
+	var groups []*parse.Group
+	total := 0
+	for i, path := range paths {
+		gs, err := parse.ParseFile(path)
+		if err != nil {
+			if _, ok := err.(*parse.ErrLine); ok {
+				err = fmt.Errorf("%s:%s", path, err)
+			}
+			fmt.Fprintln(stderr, "silk:", err)
+			return exitError
+		}
+		files[i] = gs
+		groups = append(groups, gs...)
+		for _, g := range gs {
+			total += len(g.Requests)
+		}
+	}
+	t := &silkT{out: stdout}
+	r := runner.New(t, opts.url)
+	r.Log = func(s string) { fmt.Fprintln(stdout, s) }
+	r.FollowRedirects = opts.redirects
+	r.LogCurl = opts.logCurl
+	r.Verbose = func(...interface{}) {}
+	if opts.verbose {
+		r.Verbose = func(args ...interface{}) { fmt.Fprintln(stdout, args...) }
+	}
+	if opts.openapiFile != "" {
+		spec, err := openapi.Load(opts.openapiFile)
+		if err != nil {
+			fmt.Fprintln(stderr, "silk:", err)
+			return exitError
+		}
+		r.OpenAPI = spec
+	}
+	if opts.coverFile != "" {
+		coverSpec, err := coverage.Load(opts.coverFile)
+		if err != nil {
+			fmt.Fprintln(stderr, "silk:", err)
+			return exitError
+		}
+		r.Coverage = coverage.New(coverSpec)
+	}
+	if opts.varsFiles != "" {
+		for _, filename := range strings.Split(opts.varsFiles, ",") {
+			if err := r.LoadVars(strings.TrimSpace(filename)); err != nil {
+				fmt.Fprintln(stderr, "silk:", err)
+				return exitError
+			}
+		}
+	}
+	var results []*runner.Result
+	r.Record = func(res *runner.Result) {
+		results = append(results, res)
+	}
+	// the summary goes to stderr when the report replaces the log
+	out := stdout
+	if format.quiet {
+		r.Log = func(string) {}
+		out = stderr
+	} else {
+		fmt.Fprintln(out, "silk: running", len(paths), "file(s)...")
+	}
+	start := time.Now()
+	for _, gs := range files {
+		runFile(r, gs)
+	}
+	elapsed := time.Since(start)
+
+	code := exitPassed
+	if t.failed {
+		code = exitFailed
+	}
+	if r.Coverage != nil {
+		ok, err := reportCoverage(opts, r.Coverage, out)
+		if err != nil {
+			fmt.Fprintln(stderr, "silk:", err)
+			return exitError
+		}
+		if !ok && code == exitPassed {
+			code = exitFailed
+		}
+	}
+	if err := writeResults(opts, format, paths, groups, results, stdout); err != nil {
+		fmt.Fprintln(stderr, "silk:", err)
+		return exitError
+	}
+	var passed, failed int
+	for _, res := range results {
+		if res.Passed {
+			passed++
+		} else {
+			failed++
+		}
+	}
+	fmt.Fprintln(out)
+	fmt.Fprintf(out, "silk: %d passed, %d failed, %d skipped in %s\n",
+		passed, failed, total-len(results), elapsed.Round(time.Millisecond))
+	return code
+}
+
+// runFile runs the groups of a file, which stops at the first
+// failed request.
+func runFile(r *runner.Runner, groups []*parse.Group) {
+	done := make(chan struct{})
+	go func() {
+		// FailNow exits the goroutine
+		defer close(done)
+		r.RunGroup(groups...)
+	}()
+	<-done
+}
+
+// reportCoverage writes the coverage summary, and the -silk.coverage.json
+// file, and gets whether coverage meets -silk.coverage.min.
+func reportCoverage(opts *options, cover *coverage.Report, out io.Writer) (bool, error) {
+	summary := cover.Summary()
+	fmt.Fprintln(out)
+	summary.WriteText(out)
+	if opts.coverJSON != "" {
+		f, err := os.Create(opts.coverJSON)
+		if err != nil {
+			return false, err
+		}
+		if err := summary.WriteJSON(f); err != nil {
+			f.Close()
+			return false, err
+		}
+		if err := f.Close(); err != nil {
+			return false, err
+		}
+	}
+	if summary.Percent < opts.coverMin {
+		fmt.Fprintf(out, "silk: coverage %.1f%% is below the minimum of %.1f%%\n", summary.Percent, opts.coverMin)
+		return false, nil
+	}
+	return true, nil
+}
+
+// writeResults writes the results in the -silk.report format to
+// stdout, and to the -silk.results file and the -silk.markdown reports.
+func writeResults(opts *options, format reportFormat, paths []string, groups []*parse.Group, results []*runner.Result, stdout io.Writer) error {
+	if format.write != nil {
+		if err := format.write(stdout, groups, results); err != nil {
+			return err
+		}
+	}
+	if opts.resultsFile != "" {
+		f, err := os.Create(opts.resultsFile)
+		if err != nil {
+			return err
+		}
+		if err := runner.WriteResults(f, results); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+	if opts.markdownDir != "" {
+		if err := writeMarkdown(opts.markdownDir, paths, results); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeMarkdown writes a Markdown report of each file to dir,
+// like users.report.md for users.silk.md.
+// Files with the same name get unique reports, like
+// users-2.report.md.
+func writeMarkdown(dir string, files []string, results []*runner.Result) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	names := make(map[string]bool)
+	for _, file := range files {
+		groups, err := parse.ParseFile(file)
+		if err != nil {
+			return err
+		}
+		f, err := os.Create(filepath.Join(dir, markdownName(file, names)))
+		if err != nil {
+			return err
+		}
+		if err := report.Markdown(f, groups, results); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// markdownName gets a unique name for the report of a file, like
+// users.report.md for users.silk.md.
+func markdownName(file string, names map[string]bool) string {
+	base := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(file), ".md"), ".silk")
+	name := base + ".report.md"
+	for i := 2; names[name]; i++ {
+		name = fmt.Sprintf("%s-%d.report.md", base, i)
+	}
+	names[name] = true
+	return name
+}
@@ -1,10 +1,77 @@
 package runner
 
 import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"hash"
 	"io"
+	"io/ioutil"
+	"mime"
+	"net/url"
+	"strings"
+
+	"github.com/matryer/silk/parse"
+	"github.com/matryer/silk/schema"
+	"github.com/vmihailenco/msgpack"
+	"gopkg.in/yaml.v2"
 )
 
+// BodyParser parses a response body into data for
+// Data assertions.
+// Parsers should produce the same shape as ParseJSONBody;
+// maps of type map[string]interface{}, slices of type []interface{},
+// strings, bools, nil and numbers as float64.
+type BodyParser func(r io.Reader) (interface{}, error)
+
+// DefaultBodyParsers gets the built-in body parsers, keyed by
+// media type. Keys beginning with + match structured syntax
+// suffixes, so "+xml" matches application/atom+xml.
+// JSON bodies (and bodies with no known Content-Type) are
+// parsed by Runner.ParseBody.
+func DefaultBodyParsers() map[string]BodyParser {
+	return map[string]BodyParser{
+		"application/xml":                   ParseXMLBody,
+		"text/xml":                          ParseXMLBody,
+		"+xml":                              ParseXMLBody,
+		"application/yaml":                  ParseYAMLBody,
+		"application/x-yaml":                ParseYAMLBody,
+		"text/yaml":                         ParseYAMLBody,
+		"text/x-yaml":                       ParseYAMLBody,
+		"+yaml":                             ParseYAMLBody,
+		"application/x-www-form-urlencoded": ParseFormBody,
+		"application/x-ndjson":              ParseNDJSONBody,
+		"application/ndjson":                ParseNDJSONBody,
+		"application/jsonl":                 ParseNDJSONBody,
+		"application/msgpack":               ParseMsgpackBody,
+		"application/x-msgpack":             ParseMsgpackBody,
+		"application/vnd.msgpack":           ParseMsgpackBody,
+	}
+}
+
+// bodyParser gets the function to use to parse a body
+// with the specified Content-Type.
+func (r *Runner) bodyParser(contentType string) func(r io.Reader) (interface{}, error) {
+	mediatype, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return r.ParseBody
+	}
+	if parser, ok := r.BodyParsers[mediatype]; ok {
+		return parser
+	}
+	if i := strings.LastIndex(mediatype, "+"); i > -1 {
+		if parser, ok := r.BodyParsers[mediatype[i:]]; ok {
+			return parser
+		}
+	}
+	return r.ParseBody
+}
+
 // ParseJSONBody parses a JSON body.
 func ParseJSONBody(r io.Reader) (interface{}, error) {
 	var v interface{}
@@ -13,3 +80,174 @@ func ParseJSONBody(r io.Reader) (interface{}, error) {
 	}
 	return v, nil
 }
+
+// ParseNDJSONBody parses a body of newline delimited JSON
+// values into a slice.
+func ParseNDJSONBody(r io.Reader) (interface{}, error) {
+	dec := json.NewDecoder(r)
+	values := []interface{}{}
+	for {
+		var v interface{}
+		if err := dec.Decode(&v); err != nil {
+			if err == io.EOF {
+				return values, nil
+			}
+			return nil, err
+		}
+		values = append(values, v)
+	}
+}
+
+// ParseYAMLBody parses a YAML body.
+func ParseYAMLBody(r io.Reader) (interface{}, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	if err := yaml.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	return schema.Normalize(v)
+}
+
+// ParseMsgpackBody parses a MessagePack body.
+func ParseMsgpackBody(r io.Reader) (interface{}, error) {
+	var v interface{}
+	if err := msgpack.NewDecoder(r).Decode(&v); err != nil {
+		return nil, err
+	}
+	return schema.Normalize(v)
+}
+
+// ParseFormBody parses a URL encoded form body.
+// Fields with one value are strings, repeated fields
+// are slices.
+func ParseFormBody(r io.Reader) (interface{}, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	values, err := url.ParseQuery(string(b))
+	if err != nil {
+		return nil, err
+	}
+	data := make(map[string]interface{}, len(values))
+	for k, vs := range values {
+		if len(vs) == 1 {
+			data[k] = vs[0]
+			continue
+		}
+		list := make([]interface{}, len(vs))
+		for i, v := range vs {
+			list[i] = v
+		}
+		data[k] = list
+	}
+	return data, nil
+}
+
+// ParseXMLBody parses an XML body.
+// The root element is the only key of the data. Elements that contain
+// only text are strings, other elements are maps of their children
+// with attributes prefixed with @ and any text as #text.
+// Repeated elements become slices.
+//
+//	<order id="1"><item>A</item><item>B</item></order>
+//
+// is available as Data.order.@id, Data.order.item[0] and Data.order.item[1].
+func ParseXMLBody(r io.Reader) (interface{}, error) {
+	dec := xml.NewDecoder(r)
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			if err == io.EOF {
+				return nil, errors.New("xml: missing root element")
+			}
+			return nil, err
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			v, err := parseXMLElement(dec, start)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{start.Name.Local: v}, nil
+		}
+	}
+}
+
+func parseXMLElement(dec *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	element := make(map[string]interface{})
+	for _, attr := range start.Attr {
+		element["@"+attr.Name.Local] = attr.Value
+	}
+	var text bytes.Buffer
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			v, err := parseXMLElement(dec, t)
+			if err != nil {
+				return nil, err
+			}
+			name := t.Name.Local
+			existing, ok := element[name]
+			if !ok {
+				element[name] = v
+				continue
+			}
+			if list, ok := existing.([]interface{}); ok {
+				element[name] = append(list, v)
+				continue
+			}
+			element[name] = []interface{}{existing, v}
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			s := strings.TrimSpace(text.String())
+			if len(element) == 0 {
+				return s, nil
+			}
+			if len(s) > 0 {
+				element["#text"] = s
+			}
+			return element, nil
+		}
+	}
+}
+
+// bodyDetails adds details describing the raw body,
+// useful for binary responses:
+//
+//	Body.Size: 10240
+//	Body.SHA256: "9f86d0..."
+//	Body.SHA1: "a94a8f..."
+//	Body.MD5: "098f6b..."
+//
+// Hashes are only worked out when they are expected.
+func bodyDetails(details map[string]interface{}, body []byte, expected parse.Lines) {
+	details["Body.Size"] = float64(len(body))
+	for _, line := range expected {
+		key := line.Detail().Key
+		if _, done := details[key]; done {
+			continue
+		}
+		newHash, ok := bodyHashes[key]
+		if !ok {
+			continue
+		}
+		h := newHash()
+		h.Write(body)
+		details[key] = hex.EncodeToString(h.Sum(nil))
+	}
+}
+
+// bodyHashes are the hashes bodyDetails can add.
+var bodyHashes = map[string]func() hash.Hash{
+	"Body.SHA256": sha256.New,
+	"Body.SHA1":   sha1.New,
+	"Body.MD5":    md5.New,
+}
@@ -0,0 +1,113 @@
+package runner
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"strings"
+
+	"github.com/matryer/silk/parse"
+)
+
+// cookieJarKey is the detail key that controls the cookie jar.
+// After a group heading:
+//
+//	# Group
+//	* CookieJar: true     // new jar for this group
+//	* CookieJar: "file"   // jar shared by all groups in the file
+//	* CookieJar: false    // no jar (even if Runner.Jar is set)
+//
+// Inside a request:
+//
+//	## GET /path
+//	* CookieJar: "clear"  // empty the jar before the request
+//	* CookieJar: "off"    // don't use the jar for this request
+const cookieJarKey = "CookieJar"
+
+// session holds the cookie jar used by a group of requests.
+type session struct {
+	jar http.CookieJar
+	// global is true when the jar is Runner.Jar, or the
+	// jar that replaced it when it was cleared.
+	global bool
+}
+
+// newJar makes a new empty cookie jar.
+func newJar() http.CookieJar {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		// cookiejar.New only fails with bad options
+		panic("silk: cannot make cookie jar: " + err.Error())
+	}
+	return jar
+}
+
+// groupSession gets the session for the specified group,
+// or nil if no cookie jar should be used.
+func (r *Runner) groupSession(group *parse.Group) (*session, error) {
+	for _, line := range group.Details {
+		detail := line.Detail()
+		if detail == nil || !isCookieJarDetail(detail) {
+			continue
+		}
+		switch v := detail.Value.Data; {
+		case v == true:
+			return &session{jar: newJar()}, nil
+		case v == false:
+			return nil, nil
+		case v == "file":
+			jar, ok := r.fileJars[group.Filename]
+			if !ok {
+				jar = newJar()
+				r.fileJars[group.Filename] = jar
+			}
+			return &session{jar: jar}, nil
+		default:
+			return nil, fmt.Errorf("%s:%d: invalid %s: %s (expected true, false or \"file\")", group.Filename, line.Number, cookieJarKey, detail.Value)
+		}
+	}
+	if r.Jar != nil {
+		if r.clearedJar != nil {
+			return &session{jar: r.clearedJar, global: true}, nil
+		}
+		return &session{jar: r.Jar, global: true}, nil
+	}
+	return nil, nil
+}
+
+// clear empties the jar.
+func (s *session) clear(r *Runner, filename string) {
+	s.jar = newJar()
+	if s.global {
+		r.clearedJar = s.jar
+		return
+	}
+	if _, ok := r.fileJars[filename]; ok {
+		r.fileJars[filename] = s.jar
+	}
+}
+
+// cookieDetails adds structured Cookie.<name> details
+// for each cookie set by the response.
+func cookieDetails(details map[string]interface{}, cookies []*http.Cookie) {
+	for _, cookie := range cookies {
+		prefix := "Cookie." + cookie.Name
+		details[prefix] = cookie.Value
+		details[prefix+".Name"] = cookie.Name
+		details[prefix+".Value"] = cookie.Value
+		details[prefix+".Path"] = cookie.Path
+		details[prefix+".Domain"] = cookie.Domain
+		details[prefix+".HttpOnly"] = cookie.HttpOnly
+		details[prefix+".Secure"] = cookie.Secure
+		details[prefix+".MaxAge"] = float64(cookie.MaxAge)
+		if !cookie.Expires.IsZero() {
+			details[prefix+".Expires"] = cookie.Expires.UTC().Format(http.TimeFormat)
+		}
+	}
+}
+
+// isCookieJarDetail gets whether the detail controls the
+// cookie jar rather than being a request header.
+func isCookieJarDetail(detail *parse.Detail) bool {
+	return strings.EqualFold(detail.Key, cookieJarKey)
+}
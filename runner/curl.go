@@ -0,0 +1,157 @@
+package runner
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/matryer/silk/parse"
+)
+
+// Curl gets the curl command that makes the request in the group,
+// with its variables resolved.
+// Variables declared in the group are set first, but captured
+// values are only available from earlier runs.
+func (r *Runner) Curl(group *parse.Group, req *parse.Request) (string, error) {
+	r.setGroupVars(group)
+	httpReq, body, err := r.newRequest(group, req)
+	if err != nil {
+		if lineErr, ok := err.(*lineError); ok {
+			return "", fmt.Errorf("%s:%d: %s", group.Filename, lineErr.line, lineErr)
+		}
+		return "", err
+	}
+	follow := r.FollowRedirects
+	for _, line := range req.Details {
+		if detail := line.Detail(); isFollowRedirectsDetail(detail) {
+			if f, ok := detail.Value.Data.(bool); ok {
+				follow = f
+			}
+		}
+	}
+	return r.curlCommand(group, req, httpReq, body, follow), nil
+}
+
+// curlCommand gets the curl command for req, which sends body
+// files and form parts from their files, like curl would.
+func (r *Runner) curlCommand(group *parse.Group, req *parse.Request, httpReq *http.Request, body string, follow bool) string {
+	switch {
+	case req.BodyFile != nil:
+		return curlCommand(httpReq, curlBody{args: []string{"--data-binary " + shellQuote("@"+req.BodyFile.Path)}}, follow)
+	case len(req.Parts) > 0:
+		return curlCommand(httpReq, r.curlParts(filepath.Dir(group.Filename), req.Parts), follow)
+	}
+	return CurlCommand(httpReq, body, follow)
+}
+
+// curlParts gets the -F arguments for the form parts, with
+// file paths relative to dir.
+func (r *Runner) curlParts(dir string, parts parse.Lines) curlBody {
+	body := curlBody{form: true}
+	for _, line := range parts {
+		detail := line.Detail()
+		key := r.resolveVars(detail.Key)
+		val := r.resolveVars(fmt.Sprintf("%v", detail.Value.Data))
+		if !strings.HasPrefix(val, "@") {
+			// --form-string doesn't treat @ and < in values as files
+			body.args = append(body.args, "--form-string "+shellQuote(key+"="+val))
+			continue
+		}
+		path, contentType := val[1:], "application/octet-stream"
+		if i := strings.Index(path, ";type="); i > -1 {
+			path, contentType = path[:i], path[i+len(";type="):]
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		body.args = append(body.args, "-F "+shellQuote(key+"=@"+path+";type="+contentType))
+	}
+	return body
+}
+
+// curlBody is how a request body is given to curl.
+type curlBody struct {
+	// args send the body, like --data-binary @file.
+	args []string
+	// form is whether the args are form parts, so curl sets
+	// the Content-Type with its own boundary.
+	form bool
+	// omitted is the size of a body that isn't text, so can't
+	// be written in the command.
+	omitted int
+}
+
+// CurlCommand gets the curl command for the request, quoted for
+// POSIX shells.
+// The body is passed separately since it is usually consumed.
+// Bodies that aren't text are left out, with a comment.
+func CurlCommand(req *http.Request, body string, follow bool) string {
+	if len(body) == 0 {
+		return curlCommand(req, curlBody{}, follow)
+	}
+	if !utf8.ValidString(body) || strings.ContainsRune(body, 0) {
+		return curlCommand(req, curlBody{omitted: len(body)}, follow)
+	}
+	return curlCommand(req, curlBody{args: []string{"--data-binary " + shellQuote(body)}}, follow)
+}
+
+func curlCommand(req *http.Request, body curlBody, follow bool) string {
+	// each line of the command
+	args := []string{"curl"}
+	hasBody := len(body.args) > 0 || body.omitted > 0
+	switch req.Method {
+	case "GET":
+		if hasBody {
+			args[0] += " -X GET"
+		}
+	case "HEAD":
+		args[0] += " --head"
+	default:
+		args[0] += " -X " + shellQuote(req.Method)
+	}
+	if follow {
+		args[0] += " -L"
+	}
+	args[0] += " " + shellQuote(req.URL.String())
+	keys := make([]string, 0, len(req.Header))
+	for k := range req.Header {
+		if body.form && k == "Content-Type" {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range req.Header[k] {
+			if len(v) == 0 {
+				// curl drops headers with no value
+				// unless they end with a semicolon
+				args = append(args, "-H "+shellQuote(k+";"))
+				continue
+			}
+			args = append(args, "-H "+shellQuote(k+": "+v))
+		}
+	}
+	args = append(args, body.args...)
+	cmd := strings.Join(args, " \\\n  ")
+	if body.omitted > 0 {
+		cmd = "# the " + strconv.Itoa(body.omitted) + " byte body isn't text, so it is left out\n" + cmd
+	}
+	return cmd
+}
+
+var shellSafeRegexp = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// shellQuote quotes s for POSIX shells, unless it is safe
+// as it is.
+func shellQuote(s string) string {
+	if shellSafeRegexp.MatchString(s) {
+		return s
+	}
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
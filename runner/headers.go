@@ -0,0 +1,68 @@
+package runner
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// headerDetails adds the response headers to details.
+// Every value of repeated headers is kept:
+//
+//	Vary: "Origin"       // the last value
+//	Vary[0]: "Accept"    // a specific value
+//	Vary.Count: 2        // the number of values
+//	Vary[*]: "Origin"    // any value
+func headerDetails(details map[string]interface{}, header http.Header) {
+	for k, vs := range header {
+		for i, v := range vs {
+			details[k] = v
+			details[k+"["+strconv.Itoa(i)+"]"] = v
+		}
+		details[k+".Count"] = float64(len(vs))
+		details[k+"[*]"] = vs
+	}
+}
+
+// missingDetail gets the value to use for a detail
+// that is not present in the response.
+// Missing headers have a count of zero, but other
+// details, like Data.items.Count, are still missing.
+func missingDetail(key string) (interface{}, bool) {
+	name := strings.TrimSuffix(key, ".Count")
+	if name == key || !isHeaderName(name) {
+		return nil, false
+	}
+	return float64(0), true
+}
+
+// notHeaderNames are the names of details that are not
+// headers, and a common misspelling, Cookies.
+// Other details, like Data.name, Cookie.session or XPath(//a),
+// aren't header names since they contain . [ or (.
+var notHeaderNames = map[string]bool{
+	"data":      true,
+	"body":      true,
+	"cookie":    true,
+	"cookies":   true,
+	"status":    true,
+	"url":       true,
+	"redirect":  true,
+	"redirects": true,
+	"schema":    true,
+}
+
+// isDataKey gets whether the key is an assertion about the
+// body data, like Data, Data.name or Data[0], rather than a
+// header like Data-Version.
+func isDataKey(key string) bool {
+	return key == "Data" || strings.HasPrefix(key, "Data.") || strings.HasPrefix(key, "Data[")
+}
+
+// isHeaderName gets whether name could be a response header.
+func isHeaderName(name string) bool {
+	if len(name) == 0 || strings.ContainsAny(name, ".[]()*=:\"/ ") {
+		return false
+	}
+	return !notHeaderNames[strings.ToLower(name)]
+}
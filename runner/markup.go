@@ -0,0 +1,116 @@
+package runner
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"regexp"
+	"strings"
+
+	"github.com/andybalholm/cascadia"
+	"github.com/antchfx/htmlquery"
+	"github.com/antchfx/xmlquery"
+	"github.com/antchfx/xpath"
+	"golang.org/x/net/html"
+)
+
+// markupKeyRegexp matches XPath and CSS detail keys:
+//
+//	XPath(//order/id)
+//	CSS(h1.title)
+var markupKeyRegexp = regexp.MustCompile(`^(XPath|CSS)\((.+)\)$`)
+
+// markupKey gets the kind (XPath or CSS) and expression
+// from a detail key.
+func markupKey(key string) (string, string, bool) {
+	matches := markupKeyRegexp.FindStringSubmatch(key)
+	if matches == nil {
+		return "", "", false
+	}
+	return matches[1], matches[2], true
+}
+
+// markup is a response body that is parsed as XML or HTML
+// the first time it is queried.
+type markup struct {
+	body        []byte
+	contentType string
+	html        *html.Node
+	xml         *xmlquery.Node
+	err         error
+}
+
+func (m *markup) isHTML() bool {
+	mediatype, _, err := mime.ParseMediaType(m.contentType)
+	if err != nil {
+		return false
+	}
+	return strings.Contains(mediatype, "html")
+}
+
+// navigator gets an XPath navigator for the body.
+// HTML bodies are parsed leniently, everything else as XML.
+func (m *markup) navigator() (xpath.NodeNavigator, error) {
+	if m.isHTML() {
+		doc, err := m.htmlDoc()
+		if err != nil {
+			return nil, err
+		}
+		return htmlquery.CreateXPathNavigator(doc), nil
+	}
+	if m.xml == nil && m.err == nil {
+		m.xml, m.err = xmlquery.Parse(bytes.NewReader(m.body))
+	}
+	if m.err != nil {
+		return nil, m.err
+	}
+	return xmlquery.CreateXPathNavigator(m.xml), nil
+}
+
+func (m *markup) htmlDoc() (*html.Node, error) {
+	if m.html == nil && m.err == nil {
+		m.html, m.err = html.Parse(bytes.NewReader(m.body))
+	}
+	return m.html, m.err
+}
+
+// query evaluates an XPath expression or CSS selector.
+// Node results are the text of the first node.
+// The bool is false if no nodes matched.
+func (m *markup) query(kind, expr string) (interface{}, bool, error) {
+	switch kind {
+	case "XPath":
+		compiled, err := xpath.Compile(expr)
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid XPath: %s", err)
+		}
+		nav, err := m.navigator()
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to parse body: %s", err)
+		}
+		switch result := compiled.Evaluate(nav).(type) {
+		case *xpath.NodeIterator:
+			if !result.MoveNext() {
+				return nil, false, nil
+			}
+			return strings.TrimSpace(result.Current().Value()), true, nil
+		default:
+			return result, true, nil
+		}
+	case "CSS":
+		selector, err := cascadia.Compile(expr)
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid CSS selector: %s", err)
+		}
+		doc, err := m.htmlDoc()
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to parse body: %s", err)
+		}
+		node := selector.MatchFirst(doc)
+		if node == nil {
+			return nil, false, nil
+		}
+		return strings.TrimSpace(htmlquery.InnerText(node)), true, nil
+	}
+	return nil, false, fmt.Errorf("unknown query %s", kind)
+}
@@ -0,0 +1,71 @@
+package runner
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"mime/multipart"
+	"net/textproto"
+	"path/filepath"
+	"strings"
+
+	"github.com/matryer/silk/parse"
+)
+
+// multipartBody builds a multipart/form-data body from the form parts.
+// Values beginning with @ are files, read relative to dir, with
+// an optional content type:
+//
+//	## POST /upload
+//	* +name=Silk
+//	* +avatar=@images/silk.png;type=image/png
+//
+// The body and its Content-Type are returned. A file that cannot
+// be read is a *lineError at its part's line.
+func (r *Runner) multipartBody(dir string, parts parse.Lines) (string, string, error) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	for _, line := range parts {
+		detail := line.Detail()
+		key := r.resolveVars(detail.Key)
+		val := r.resolveVars(fmt.Sprintf("%v", detail.Value.Data))
+		r.Verbose(indent, "+"+key+"="+val)
+		if !strings.HasPrefix(val, "@") {
+			if err := w.WriteField(key, val); err != nil {
+				return "", "", err
+			}
+			continue
+		}
+		path, contentType := val[1:], "application/octet-stream"
+		if i := strings.Index(path, ";type="); i > -1 {
+			path, contentType = path[:i], path[i+len(";type="):]
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return "", "", &lineError{line: line.Number, args: []interface{}{"- invalid form part:", err}}
+		}
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, escapeQuotes(key), escapeQuotes(filepath.Base(path))))
+		h.Set("Content-Type", contentType)
+		part, err := w.CreatePart(h)
+		if err != nil {
+			return "", "", err
+		}
+		if _, err := part.Write(b); err != nil {
+			return "", "", err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return "", "", err
+	}
+	return buf.String(), w.FormDataContentType(), nil
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
+}
@@ -0,0 +1,27 @@
+package runner
+
+import "net/http"
+
+// assertContract checks the request and response against their
+// operations in r.OpenAPI, logging every violation.
+// The response is checked against the operation of the last
+// request, in case redirects were followed.
+func (r *Runner) assertContract(req *http.Request, reqBody []byte, lastReq *http.Request, res *http.Response, resBody []byte) bool {
+	op := r.OpenAPI.Find(req.Method, req.URL.Path)
+	if op == nil {
+		r.log("OpenAPI", "no operation for", req.Method, req.URL.Path)
+		return false
+	}
+	violations := op.CheckRequest(req, reqBody)
+	if lastReq != req {
+		if op = r.OpenAPI.Find(lastReq.Method, lastReq.URL.Path); op == nil {
+			r.log("OpenAPI", "no operation for", lastReq.Method, lastReq.URL.Path)
+			return false
+		}
+	}
+	violations = append(violations, op.CheckResponse(res.StatusCode, res.Header, resBody)...)
+	for _, v := range violations {
+		r.log("OpenAPI", v)
+	}
+	return len(violations) == 0
+}
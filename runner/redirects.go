@@ -0,0 +1,178 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/matryer/silk/parse"
+)
+
+// followRedirectsKey is the request detail that turns following
+// redirects on or off for a single request.
+const followRedirectsKey = "FollowRedirects"
+
+// maxRedirects is the number of redirects that will be followed
+// before giving up.
+const maxRedirects = 10
+
+// redirect describes a single redirect response.
+type redirect struct {
+	URL      string
+	Status   int
+	Location string
+}
+
+// isFollowRedirectsDetail gets whether the detail controls
+// redirects rather than being a request header.
+func isFollowRedirectsDetail(detail *parse.Detail) bool {
+	return strings.EqualFold(detail.Key, followRedirectsKey)
+}
+
+func isRedirect(res *http.Response) bool {
+	switch res.StatusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return res.Header.Get("Location") != ""
+	}
+	return false
+}
+
+// do performs the request, optionally following redirects.
+// Cookies are sent from, and stored in, jar if it is not nil.
+// body is the request body, which is sent again for
+// 307 and 308 redirects.
+// The last request made is returned along with its response.
+func (r *Runner) do(httpReq *http.Request, body string, jar http.CookieJar, follow bool) (*http.Request, *http.Response, []redirect, error) {
+	var redirects []redirect
+	first := httpReq.URL
+	for {
+		// the request's own Cookie header, without the jar's cookies
+		cookieHeader := httpReq.Header["Cookie"]
+		if jar != nil {
+			for _, cookie := range jar.Cookies(httpReq.URL) {
+				httpReq.AddCookie(cookie)
+			}
+		}
+		httpRes, err := r.DoRequest(httpReq)
+		if err != nil {
+			return httpReq, nil, redirects, err
+		}
+		if jar != nil {
+			jar.SetCookies(httpReq.URL, httpRes.Cookies())
+		}
+		if !follow || !isRedirect(httpRes) {
+			return httpReq, httpRes, redirects, nil
+		}
+		if len(redirects) == maxRedirects {
+			httpRes.Body.Close()
+			return httpReq, nil, redirects, errors.New("stopped after " + strconv.Itoa(maxRedirects) + " redirects")
+		}
+		location := httpRes.Header.Get("Location")
+		redirects = append(redirects, redirect{
+			URL:      httpReq.URL.String(),
+			Status:   httpRes.StatusCode,
+			Location: location,
+		})
+		// discard the redirect body
+		io.Copy(ioutil.Discard, httpRes.Body)
+		httpRes.Body.Close()
+		next, err := httpReq.URL.Parse(location)
+		if err != nil {
+			return httpReq, nil, redirects, fmt.Errorf("bad redirect location %q: %s", location, err)
+		}
+		r.Verbose(indent, httpRes.StatusCode, "->", next)
+		method := httpReq.Method
+		var nextBody io.Reader
+		keepBody := httpRes.StatusCode == http.StatusTemporaryRedirect || httpRes.StatusCode == http.StatusPermanentRedirect
+		if keepBody && len(body) > 0 {
+			nextBody = strings.NewReader(body)
+		}
+		if !keepBody && method != "GET" && method != "HEAD" {
+			method = "GET"
+		}
+		nextReq, err := r.NewRequest(method, next.String(), nextBody)
+		if err != nil {
+			return httpReq, nil, redirects, err
+		}
+		for k, vs := range httpReq.Header {
+			if k == "Cookie" {
+				// the jar's cookies are added again for the next URL
+				vs = cookieHeader
+				if len(vs) == 0 {
+					continue
+				}
+			}
+			if !keepBody && (k == "Content-Type" || k == "Content-Length") {
+				continue
+			}
+			if sensitiveHeaders[k] && !sameHost(first, next) {
+				continue
+			}
+			nextReq.Header[k] = vs
+		}
+		if keepBody {
+			nextReq.ContentLength = int64(len(body))
+		}
+		httpReq = nextReq
+	}
+}
+
+// sensitiveHeaders are only sent on redirects to the same
+// host as the first request, or one of its subdomains.
+var sensitiveHeaders = map[string]bool{
+	"Authorization":    true,
+	"Www-Authenticate": true,
+	"Cookie":           true,
+	"Cookie2":          true,
+}
+
+// sameHost gets whether dest is the same host as from, or a
+// subdomain of it, like net/http does when deciding which
+// headers to copy onto redirects.
+func sameHost(from, dest *url.URL) bool {
+	fromHost := strings.ToLower(canonicalAddr(from))
+	destHost := strings.ToLower(canonicalAddr(dest))
+	if fromHost == destHost {
+		return true
+	}
+	sub := len(destHost) - len(fromHost)
+	return sub > 0 && destHost[sub-1] == '.' && destHost[sub:] == fromHost
+}
+
+// canonicalAddr gets the host and port of u, with the default
+// port for the scheme if there isn't one.
+func canonicalAddr(u *url.URL) string {
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+	return u.Hostname() + ":" + port
+}
+
+// redirectDetails adds details describing the redirects
+// and the final URL:
+//
+//	Redirects: 2
+//	Redirect[0].Status: 302
+//	Redirect[0].Location: "/login"
+//	Redirect[0].URL: "http://localhost/account"
+//	URL: "http://localhost/login"
+func redirectDetails(details map[string]interface{}, redirects []redirect, finalURL string) {
+	details["Redirects"] = float64(len(redirects))
+	for i, redirect := range redirects {
+		prefix := "Redirect[" + strconv.Itoa(i) + "]"
+		details[prefix+".URL"] = redirect.URL
+		details[prefix+".Status"] = float64(redirect.Status)
+		details[prefix+".Location"] = redirect.Location
+	}
+	details["URL"] = finalURL
+}
@@ -0,0 +1,113 @@
+package runner
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Result is the outcome of running a request.
+type Result struct {
+	// Filename is the document the request is in.
+	Filename string `json:"filename"`
+	// Group is the title of the group.
+	Group string `json:"group"`
+	// Line is the line of the request heading.
+	Line   int    `json:"line"`
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	// URL is the URL requested, with variables resolved.
+	URL    string `json:"url,omitempty"`
+	Passed bool   `json:"passed"`
+	// Failure describes why the request failed, or is nil
+	// if it passed.
+	Failure *Failure `json:"failure,omitempty"`
+	// Response is the response, or nil if none was received.
+	Response *Response     `json:"response,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Failure describes why a request failed.
+type Failure struct {
+	// Line is the line of the failing assertion.
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+	// Key is the detail that doesn't match, like Status or
+	// Data.name, or Body.
+	Key      string `json:"key,omitempty"`
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+	// Log is everything logged while running the request.
+	Log []string `json:"log,omitempty"`
+}
+
+// Response is a response received by a request.
+type Response struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   string      `json:"body"`
+}
+
+// failed records that the request failed at line.
+func (res *Result) failed(line int, message string) {
+	if res.Failure == nil {
+		res.Failure = &Failure{}
+	}
+	res.Failure.Line = line
+	res.Failure.Message = strings.TrimPrefix(message, "- ")
+}
+
+// FindResult gets the last result for the request at line of
+// filename, or nil if it wasn't run.
+// Results are matched by path, or by the file name if they were
+// run from a different directory.
+func FindResult(results []*Result, filename string, line int) *Result {
+	var byPath, byName *Result
+	for _, res := range results {
+		if res.Line != line {
+			continue
+		}
+		if filepath.Clean(res.Filename) == filepath.Clean(filename) {
+			byPath = res
+		} else if filepath.Base(res.Filename) == filepath.Base(filename) {
+			byName = res
+		}
+	}
+	if byPath != nil {
+		return byPath
+	}
+	return byName
+}
+
+// WriteResults writes results as JSON.
+func WriteResults(w io.Writer, results []*Result) error {
+	if results == nil {
+		results = []*Result{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(results)
+}
+
+// ReadResults reads results written by WriteResults.
+func ReadResults(r io.Reader) ([]*Result, error) {
+	var results []*Result
+	if err := json.NewDecoder(r).Decode(&results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// LoadResults reads the results in the file at path.
+func LoadResults(path string) ([]*Result, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadResults(f)
+}
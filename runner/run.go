@@ -7,15 +7,21 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strconv"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
-	"github.com/matryer/m"
+	"github.com/matryer/silk/coverage"
+	"github.com/matryer/silk/openapi"
 	"github.com/matryer/silk/parse"
+	"github.com/matryer/silk/query"
+	"github.com/matryer/silk/schema"
 )
 
 const indent = " "
@@ -29,21 +35,59 @@ type T interface {
 
 // Runner runs parsed tests.
 type Runner struct {
-	t       T
-	rootURL string
-	vars    map[string]*parse.Value
+	t        T
+	rootURL  string
+	vars     map[string]*parse.Value
+	fileJars map[string]http.CookieJar
+	// clearedJar is used instead of Jar once it has been
+	// cleared, leaving the caller's jar alone.
+	clearedJar http.CookieJar
+	// fileVars are the names of variables loaded by LoadVars.
+	fileVars map[string]bool
+	// curl makes the curl command for the current request,
+	// logged if it fails.
+	curl func() string
+	// result is the result of the current request.
+	result *Result
 	// DoRequest makes the request and returns the response.
 	// By default uses http.DefaultClient.Do.
 	DoRequest func(r *http.Request) (*http.Response, error)
 	// ParseBody is the function to use to attempt to parse
 	// response bodies to make data available for assertions.
+	// It is used when no BodyParsers match the Content-Type,
+	// so to parse every body with it, set BodyParsers to nil.
 	ParseBody func(r io.Reader) (interface{}, error)
+	// BodyParsers are the functions used to parse response bodies
+	// keyed by media type, and take precedence over ParseBody.
+	// By default, DefaultBodyParsers.
+	BodyParsers map[string]BodyParser
 	// Log is the function to log to.
 	Log func(string)
 	// Verbose is the function that logs verbose debug information.
 	Verbose func(...interface{})
 	// NewRequest makes a new http.Request. By default, uses http.NewRequest.
 	NewRequest func(method, urlStr string, body io.Reader) (*http.Request, error)
+	// Jar is an optional cookie jar shared by every request. When set,
+	// cookies from responses are sent back on later requests.
+	// Groups can opt in to their own jar with the CookieJar detail.
+	// Clearing it (with CookieJar: "clear") switches to a new empty
+	// jar for the rest of the run, without changing Jar.
+	Jar http.CookieJar
+	// FollowRedirects is whether redirect responses are followed.
+	// Requests may override it with the FollowRedirects detail.
+	FollowRedirects bool
+	// OpenAPI is an optional specification that every request
+	// and response is checked against.
+	OpenAPI *openapi.Spec
+	// Coverage is an optional report that records every request
+	// and the status of its response.
+	Coverage *coverage.Report
+	// LogCurl is whether an equivalent curl command is logged
+	// when a request fails.
+	LogCurl bool
+	// Record is an optional function called with the result
+	// of every request that is run.
+	Record func(*Result)
 }
 
 // New makes a new Runner with the given testing T target and the
@@ -53,6 +97,8 @@ func New(t T, URL string) *Runner {
 		t:         t,
 		rootURL:   URL,
 		vars:      make(map[string]*parse.Value),
+		fileJars:  make(map[string]http.CookieJar),
+		fileVars:  make(map[string]bool),
 		DoRequest: http.DefaultTransport.RoundTrip,
 		Log: func(s string) {
 			fmt.Println(s)
@@ -63,8 +109,9 @@ func New(t T, URL string) *Runner {
 			}
 			fmt.Println(args...)
 		},
-		ParseBody:  ParseJSONBody,
-		NewRequest: http.NewRequest,
+		ParseBody:   ParseJSONBody,
+		BodyParsers: DefaultBodyParsers(),
+		NewRequest:  http.NewRequest,
 	}
 	// capture environment variables by default
 	for _, e := range os.Environ() {
@@ -80,7 +127,14 @@ func (r *Runner) log(args ...interface{}) {
 		strs = append(strs, fmt.Sprint(arg))
 	}
 	strs = append(strs, " ")
-	r.Log(strings.Join(strs, " "))
+	line := strings.Join(strs, " ")
+	if r.result != nil {
+		if r.result.Failure == nil {
+			r.result.Failure = &Failure{}
+		}
+		r.result.Failure.Log = append(r.result.Failure.Log, line)
+	}
+	r.Log(line)
 }
 
 // RunGlob is a helper that runs the files returned by filepath.Glob.
@@ -113,93 +167,135 @@ func (r *Runner) RunGroup(groups ...*parse.Group) {
 }
 
 func (r *Runner) runGroup(group *parse.Group) {
+	r.setGroupVars(group)
+	s, err := r.groupSession(group)
+	if err != nil {
+		r.log(err)
+		r.t.FailNow()
+		return
+	}
 	for _, req := range group.Requests {
-		r.runRequest(group, req)
+		r.runRequest(group, req, s)
 	}
 }
 
-func (r *Runner) runRequest(group *parse.Group, req *parse.Request) {
-	m := string(req.Method)
-	p := string(req.Path)
-	absPath := r.resolveVars(r.rootURL + p)
-	m = r.resolveVars(m)
-	r.Verbose(string(req.Method), absPath)
-	var body io.Reader
-	var bodyStr string
-	if len(req.Body) > 0 {
-		bodyStr = r.resolveVars(req.Body.String())
-		body = strings.NewReader(bodyStr)
-	}
-	// make request
-	httpReq, err := r.NewRequest(m, absPath, body)
+func (r *Runner) runRequest(group *parse.Group, req *parse.Request, s *session) {
+	r.curl = nil
+	res := &Result{
+		Filename: group.Filename,
+		Group:    string(group.Title),
+		Line:     req.Line,
+		Method:   string(req.Method),
+		Path:     string(req.Path),
+	}
+	r.result = res
+	start := time.Now()
+	// failed requests stop the test, so record in a defer
+	defer func() {
+		r.result = nil
+		res.Duration = time.Since(start)
+		if res.Failure != nil && res.Failure.Message == "" {
+			// logged without failing
+			res.Failure = nil
+		}
+		res.Passed = res.Failure == nil
+		if r.Record != nil {
+			r.Record(res)
+		}
+	}()
+	httpReq, bodyStr, err := r.newRequest(group, req)
 	if err != nil {
-		r.log("invalid request: ", err)
-		r.t.FailNow()
+		if lineErr, ok := err.(*lineError); ok {
+			r.fail(group, req, lineErr.line, lineErr.args...)
+			return
+		}
+		r.failNow(req.Line, "invalid request: ", err)
 		return
 	}
-	// set body
+	res.URL = httpReq.URL.String()
 	bodyLen := len(bodyStr)
-	if bodyLen > 0 {
-		httpReq.ContentLength = int64(bodyLen)
-	}
-	// set request headers
+	// request options
+	useJar := s != nil
+	follow := r.FollowRedirects
 	for _, line := range req.Details {
 		detail := line.Detail()
-		val := fmt.Sprintf("%v", detail.Value.Data)
-		val = r.resolveVars(val)
-		detail.Value = parse.ParseValue([]byte(val))
-		r.Verbose(indent, detail.String())
-		httpReq.Header.Add(detail.Key, val)
+		switch {
+		case isCookieJarDetail(detail):
+			switch detail.Value.Data {
+			case "clear":
+				if s != nil {
+					s.clear(r, group.Filename)
+				}
+			case "off":
+				useJar = false
+			default:
+				r.fail(group, req, line.Number, "- invalid "+cookieJarKey+` (expected "clear" or "off")`)
+				return
+			}
+		case isFollowRedirectsDetail(detail):
+			var ok bool
+			if follow, ok = detail.Value.Data.(bool); !ok {
+				r.fail(group, req, line.Number, "- invalid "+followRedirectsKey+" (expected true or false)")
+				return
+			}
+		}
 	}
-	// set parameters
-	q := httpReq.URL.Query()
-	for _, line := range req.Params {
-		detail := line.Detail()
-		val := fmt.Sprintf("%v", detail.Value.Data)
-		val = r.resolveVars(val)
-		detail.Value = parse.ParseValue([]byte(val))
-		r.Verbose(indent, detail.String())
-		q.Add(detail.Key, val)
+	if r.LogCurl {
+		// made when logged, to include cookies from the jar
+		r.curl = func() string { return r.curlCommand(group, req, httpReq, bodyStr, follow) }
 	}
-	httpReq.URL.RawQuery = q.Encode()
-
 	// print request body
-	if bodyLen > 0 {
+	if req.BodyFile != nil {
+		r.Verbose("(body from", req.BodyFile.Path, "-", bodyLen, "bytes)")
+	} else if bodyLen > 0 && len(req.Parts) == 0 {
 		r.Verbose("```")
 		r.Verbose(bodyStr)
 		r.Verbose("```")
 	}
 	// perform request
-	httpRes, err := r.DoRequest(httpReq)
+	var jar http.CookieJar
+	if useJar {
+		jar = s.jar
+	}
+	lastReq, httpRes, redirects, err := r.do(httpReq, bodyStr, jar, follow)
 	if err != nil {
-		r.log(err)
-		r.t.FailNow()
+		r.failNow(req.Line, err)
 		return
 	}
+	if r.Coverage != nil {
+		// the first response is the one for this request
+		status := httpRes.StatusCode
+		if len(redirects) > 0 {
+			status = redirects[0].Status
+		}
+		r.Coverage.Record(httpReq.Method, r.routePath(httpReq.URL.Path), status)
+	}
 
 	// collect response details
 	responseDetails := make(map[string]interface{})
-	for k, vs := range httpRes.Header {
-		for _, v := range vs {
-			responseDetails[k] = v
-		}
-	}
+	headerDetails(responseDetails, httpRes.Header)
 	// add cookies to repsonse details
 	var cookieStrs []string
 	for _, cookie := range httpRes.Cookies() {
 		cookieStrs = append(cookieStrs, cookie.String())
 	}
 	responseDetails["Set-Cookie"] = strings.Join(cookieStrs, "|")
+	cookieDetails(responseDetails, httpRes.Cookies())
 
 	// set other details
 	responseDetails["Status"] = float64(httpRes.StatusCode)
+	redirectDetails(responseDetails, redirects, lastReq.URL.String())
 
 	actualBody, err := ioutil.ReadAll(httpRes.Body)
 	if err != nil {
-		r.log("failed to read body: ", err)
-		r.t.FailNow()
+		r.failNow(req.Line, "failed to read body: ", err)
 		return
 	}
+	res.Response = &Response{
+		Status: httpRes.StatusCode,
+		Header: httpRes.Header,
+		Body:   string(actualBody),
+	}
 	if len(actualBody) > 0 {
 		r.Verbose("```")
 		r.Verbose(string(actualBody))
@@ -208,16 +304,62 @@ func (r *Runner) runRequest(group *parse.Group, req *parse.Request) {
 
 	// set the body as a field (see issue #15)
 	responseDetails["Body"] = string(actualBody)
+	bodyDetails(responseDetails, actualBody, req.ExpectedDetails)
 
 	/*
 		Assertions
 		---------------------------------------------------------
 	*/
 
+	// parse the body data the first time it's needed
+	var parseDataOnce sync.Once
+	var data interface{}
+	var errData error
+	parseData := func() (interface{}, error) {
+		parseDataOnce.Do(func() {
+			parseBody := r.bodyParser(httpRes.Header.Get("Content-Type"))
+			data, errData = parseBody(bytes.NewReader(actualBody))
+		})
+		return data, errData
+	}
+
 	// assert the body
-	if len(req.ExpectedBody) > 0 {
-		// check body against expected body
-		exp := r.resolveVars(req.ExpectedBody.String())
+	hasExpectedBody := len(req.ExpectedBody) > 0
+	expectedBodyLine := req.ExpectedBody.Number()
+	var exp string
+	if hasExpectedBody {
+		exp = r.resolveVars(req.ExpectedBody.String())
+	}
+	if req.ExpectedBodyFile != nil {
+		// files are compared verbatim, without resolving variables
+		b, err := ioutil.ReadFile(req.ExpectedBodyFile.Path)
+		if err != nil {
+			r.fail(group, req, req.ExpectedBodyFile.Line, "- cannot read expected body:", err)
+			return
+		}
+		hasExpectedBody = true
+		expectedBodyLine = req.ExpectedBodyFile.Line
+		exp = string(b)
+	}
+	if req.ExpectedBodyType == schemaBodyType {
+		// the expected body is a JSON Schema for the data
+		var sch *schema.Schema
+		var err error
+		if req.ExpectedBodyFile != nil {
+			sch, err = schema.Load(req.ExpectedBodyFile.Path)
+		} else {
+			sch, err = schema.Parse([]byte(exp), group.Filename)
+		}
+		if err != nil {
+			r.fail(group, req, expectedBodyLine, "- invalid schema:", err)
+			return
+		}
+		data, errData := parseData()
+		if !r.assertSchema(sch, data, errData) {
+			r.fail(group, req, expectedBodyLine, "- body doesn't match schema")
+			return
+		}
+	} else if hasExpectedBody {
 
 		// depending on the expectedBodyType:
 		// json*: check if expectedBody as JSON is a subset of the actualBody as json
@@ -235,23 +377,23 @@ func (r *Runner) runRequest(group *parse.Group, req *parse.Request) {
 			if !strings.Contains(req.ExpectedBodyType, "exact") {
 				eq, err := r.assertJSONIsEqualOrSubset(expectedJSON, actualJSON)
 				if !eq {
-					r.fail(group, req, req.ExpectedBody.Number(), "- body doesn't match", err)
+					r.mismatch("Body", exp, string(actualBody))
+					r.fail(group, req, expectedBodyLine, "- body doesn't match", err)
 					return
 				}
 			} else if !reflect.DeepEqual(actualJSON, expectedJSON) {
-				r.fail(group, req, req.ExpectedBody.Number(), "- body doesn't match")
+				r.mismatch("Body", exp, string(actualBody))
+				r.fail(group, req, expectedBodyLine, "- body doesn't match")
 				return
 			}
 		} else if !r.assertBody(actualBody, []byte(exp)) {
-			r.fail(group, req, req.ExpectedBody.Number(), "- body doesn't match")
+			r.fail(group, req, expectedBodyLine, "- body doesn't match")
 			return
 		}
 	}
 
 	// assert the details
-	var parseDataOnce sync.Once
-	var data interface{}
-	var errData error
+	doc := &markup{body: actualBody, contentType: httpRes.Header.Get("Content-Type")}
 	if len(req.ExpectedDetails) > 0 {
 		for _, line := range req.ExpectedDetails {
 			detail := line.Detail()
@@ -259,20 +401,43 @@ func (r *Runner) runRequest(group *parse.Group, req *parse.Request) {
 			if detail.Value.Type() == "string" {
 				detail.Value.Data = r.resolveVars(detail.Value.Data.(string))
 			}
-			if strings.HasPrefix(detail.Key, "Data") {
-				parseDataOnce.Do(func() {
-					data, errData = r.ParseBody(bytes.NewReader(actualBody))
-				})
+			if detail.Key == schemaKey {
+				ref, _ := detail.Value.Data.(string)
+				sch, err := loadSchema(filepath.Dir(group.Filename), ref)
+				if err != nil {
+					r.fail(group, req, line.Number, "- invalid schema:", err)
+					return
+				}
+				data, errData := parseData()
+				if !r.assertSchema(sch, data, errData) {
+					r.fail(group, req, line.Number, "- "+detail.Key+" doesn't match")
+					return
+				}
+				continue
+			}
+			if isDataKey(detail.Key) {
+				data, errData := parseData()
 				if !r.assertData(line, data, errData, detail.Key, detail.Value) {
 					r.fail(group, req, line.Number, "- "+detail.Key+" doesn't match")
 					return
 				}
 				continue
 			}
+			if kind, expr, ok := markupKey(detail.Key); ok {
+				actual, found, err := doc.query(kind, r.resolveVars(expr))
+				if !r.assertMarkup(line, detail.Key, actual, found, err, detail.Value) {
+					r.fail(group, req, line.Number, "- "+detail.Key+" doesn't match")
+					return
+				}
+				continue
+			}
 			var actual interface{}
 			var present bool
 			if actual, present = responseDetails[detail.Key]; !present {
-				r.log(detail.Key, fmt.Sprintf("expected %s: %s  actual %T: %s", detail.Value.Type(), detail.Value, actual, "(missing)"))
+				actual, present = missingDetail(detail.Key)
+			}
+			if !present {
+				r.logMismatch(detail.Key, detail.Value.Type(), detail.Value.String(), fmt.Sprintf("%T", actual), "(missing)")
 				r.fail(group, req, line.Number, "- "+detail.Key+" doesn't match")
 				return
 			}
@@ -283,6 +448,140 @@ func (r *Runner) runRequest(group *parse.Group, req *parse.Request) {
 		}
 	}
 
+	// check the contract, after the document's own assertions
+	if r.OpenAPI != nil && !r.assertContract(httpReq, []byte(bodyStr), lastReq, httpRes, actualBody) {
+		r.fail(group, req, req.Line, "- doesn't match the OpenAPI spec")
+		return
+	}
+
+}
+
+// newRequest makes the http.Request for req, with variables
+// resolved, and gets its body.
+// Errors in the document are of type *lineError.
+func (r *Runner) newRequest(group *parse.Group, req *parse.Request) (*http.Request, string, error) {
+	m := string(req.Method)
+	p := string(req.Path)
+	absPath := r.resolveVars(r.rootURL + p)
+	m = r.resolveVars(m)
+	r.Verbose(string(req.Method), absPath)
+	var body io.Reader
+	var bodyStr string
+	var contentType string
+	if len(req.Body) > 0 {
+		bodyStr = r.resolveVars(req.Body.String())
+		body = strings.NewReader(bodyStr)
+	}
+	if req.BodyFile != nil {
+		if len(req.Body) > 0 {
+			return nil, "", &lineError{line: req.BodyFile.Line, args: []interface{}{"- cannot have a body and a body file"}}
+		}
+		// files are sent verbatim, without resolving variables
+		b, err := ioutil.ReadFile(req.BodyFile.Path)
+		if err != nil {
+			return nil, "", &lineError{line: req.BodyFile.Line, args: []interface{}{"- cannot read body:", err}}
+		}
+		bodyStr = string(b)
+		body = strings.NewReader(bodyStr)
+	}
+	if len(req.Parts) > 0 {
+		if len(req.Body) > 0 || req.BodyFile != nil {
+			line := req.Body.Number()
+			if req.BodyFile != nil {
+				line = req.BodyFile.Line
+			}
+			return nil, "", &lineError{line: line, args: []interface{}{"- cannot have a body and form parts"}}
+		}
+		var err error
+		bodyStr, contentType, err = r.multipartBody(filepath.Dir(group.Filename), req.Parts)
+		if lineErr, ok := err.(*lineError); ok {
+			return nil, "", lineErr
+		}
+		if err != nil {
+			return nil, "", &lineError{line: req.Parts.Number(), args: []interface{}{"- invalid form parts:", err}}
+		}
+		body = strings.NewReader(bodyStr)
+	}
+	if len(req.Form) > 0 {
+		if len(req.Body) > 0 || req.BodyFile != nil || len(req.Parts) > 0 {
+			return nil, "", &lineError{line: req.Form.Number(), args: []interface{}{"- cannot have form fields with a body or form parts"}}
+		}
+		form := url.Values{}
+		for _, line := range req.Form {
+			detail := line.Detail()
+			val := r.resolveVars(fmt.Sprintf("%v", detail.Value.Data))
+			r.Verbose(indent, "&"+detail.Key+"="+val)
+			form.Add(r.resolveVars(detail.Key), val)
+		}
+		bodyStr = form.Encode()
+		body = strings.NewReader(bodyStr)
+		contentType = "application/x-www-form-urlencoded"
+	}
+	// make request
+	httpReq, err := r.NewRequest(m, absPath, body)
+	if err != nil {
+		return nil, "", err
+	}
+	// set body
+	if len(bodyStr) > 0 {
+		httpReq.ContentLength = int64(len(bodyStr))
+	}
+	// set request headers
+	for _, line := range req.Details {
+		detail := line.Detail()
+		if isCookieJarDetail(detail) || isFollowRedirectsDetail(detail) {
+			// options, rather than headers
+			continue
+		}
+		val := fmt.Sprintf("%v", detail.Value.Data)
+		val = r.resolveVars(val)
+		detail.Value = parse.ParseValue([]byte(val))
+		r.Verbose(indent, detail.String())
+		httpReq.Header.Add(detail.Key, val)
+	}
+	if contentType != "" && httpReq.Header.Get("Content-Type") == "" {
+		httpReq.Header.Set("Content-Type", contentType)
+	}
+	// set parameters
+	q := httpReq.URL.Query()
+	for _, line := range req.Params {
+		detail := line.Detail()
+		val := fmt.Sprintf("%v", detail.Value.Data)
+		val = r.resolveVars(val)
+		detail.Value = parse.ParseValue([]byte(val))
+		r.Verbose(indent, detail.String())
+		q.Add(detail.Key, val)
+	}
+	httpReq.URL.RawQuery = q.Encode()
+	return httpReq, bodyStr, nil
+}
+
+// lineError is an error in a request, at a line of the document.
+type lineError struct {
+	line int
+	args []interface{}
+}
+
+func (e *lineError) Error() string {
+	return strings.TrimSpace(fmt.Sprintln(e.args...))
+}
+
+// routePath gets the path relative to the path of the root URL
+// (like /api in http://localhost/api), which is how specs and
+// route lists describe it.
+func (r *Runner) routePath(p string) string {
+	root, err := url.Parse(r.rootURL)
+	if err != nil {
+		return p
+	}
+	base := strings.TrimSuffix(root.Path, "/")
+	if len(base) == 0 || (p != base && !strings.HasPrefix(p, base+"/")) {
+		return p
+	}
+	if p = p[len(base):]; len(p) == 0 {
+		return "/"
+	}
+	return p
 }
 
 func (r *Runner) resolveVars(s string) string {
@@ -296,9 +595,57 @@ func (r *Runner) resolveVars(s string) string {
 func (r *Runner) fail(group *parse.Group, req *parse.Request, line int, args ...interface{}) {
 	logargs := []interface{}{"--- FAIL:", string(req.Method), string(req.Path), "\n", group.Filename + ":" + strconv.FormatInt(int64(line), 10)}
 	r.log(append(logargs, args...)...)
+	if r.result != nil {
+		r.result.failed(line, strings.TrimSpace(fmt.Sprintln(args...)))
+	}
+	if r.curl != nil {
+		r.log(r.curl())
+	}
+	r.t.FailNow()
+}
+
+// failNow logs args and stops, for failures that are not
+// caused by an assertion, like network errors.
+func (r *Runner) failNow(line int, args ...interface{}) {
+	r.log(args...)
+	if r.result != nil {
+		r.result.failed(line, fmt.Sprint(args...))
+	}
+	if r.curl != nil {
+		r.log(r.curl())
+	}
 	r.t.FailNow()
 }
 
+// logMismatch logs that the value of key doesn't match the expected
+// value, and records the values in the result.
+// The types are included in the log if they are not empty.
+func (r *Runner) logMismatch(key, expectedType, expected, actualType, actual string) {
+	e, a := "expected", "actual"
+	if len(expectedType) > 0 {
+		e += " " + expectedType
+	}
+	if len(actualType) > 0 {
+		a += " " + actualType
+	}
+	r.log(key, fmt.Sprintf("%s: %s  %s: %s", e, expected, a, actual))
+	r.mismatch(key, expected, actual)
+}
+
+// mismatch records the expected and actual values of key
+// in the result.
+func (r *Runner) mismatch(key, expected, actual string) {
+	if r.result == nil {
+		return
+	}
+	if r.result.Failure == nil {
+		r.result.Failure = &Failure{}
+	}
+	r.result.Failure.Key = key
+	r.result.Failure.Expected = expected
+	r.result.Failure.Actual = actual
+}
+
 func (r *Runner) assertBody(actual, expected []byte) bool {
 	if !reflect.DeepEqual(actual, expected) {
 		r.log("body expected:")
@@ -310,12 +657,23 @@ func (r *Runner) assertBody(actual, expected []byte) bool {
 		r.log("```")
 		r.log(string(actual))
 		r.log("```")
+		r.mismatch("Body", string(expected), string(actual))
 		return false
 	}
 	return true
 }
 
 func (r *Runner) assertDetail(line *parse.Line, key string, actual interface{}, expected *parse.Value) bool {
+	if values, ok := actual.([]string); ok {
+		// any of the values may match
+		for _, v := range values {
+			if expected.Equal(v) {
+				return r.assertDetail(line, key, v, expected)
+			}
+		}
+		r.logMismatch(key, "any", expected.String(), "", fmt.Sprintf("%q", values))
+		return false
+	}
 	if !expected.Equal(actual) {
 		actualVal := parse.ParseValue([]byte(fmt.Sprintf("%v", actual)))
 		actualString := actualVal.String()
@@ -324,9 +682,9 @@ func (r *Runner) assertDetail(line *parse.Line, key string, actual interface{},
 		}
 
 		if expected.Type() == actualVal.Type() {
-			r.log(key, fmt.Sprintf("expected: %s  actual: %s", expected, actualString))
+			r.logMismatch(key, "", expected.String(), "", actualString)
 		} else {
-			r.log(key, fmt.Sprintf("expected %s: %s  actual %T: %s", expected.Type(), expected, actual, actualString))
+			r.logMismatch(key, expected.Type(), expected.String(), fmt.Sprintf("%T", actual), actualString)
 		}
 
 		return false
@@ -340,16 +698,29 @@ func (r *Runner) assertDetail(line *parse.Line, key string, actual interface{},
 
 func (r *Runner) assertData(line *parse.Line, data interface{}, errData error, key string, expected *parse.Value) bool {
 	if errData != nil {
-		r.log(key, fmt.Sprintf("expected %s: %s  actual: failed to parse body: %s", expected.Type(), expected, errData))
+		r.logMismatch(key, expected.Type(), expected.String(), "", "failed to parse body: "+errData.Error())
 		return false
 	}
 	if data == nil {
-		r.log(key, fmt.Sprintf("expected %s: %s  actual: no data", expected.Type(), expected))
+		r.logMismatch(key, expected.Type(), expected.String(), "", "no data")
+		return false
+	}
+	q, err := query.Parse(key)
+	if err != nil {
+		r.log(key, err)
 		return false
 	}
-	actual, ok := m.GetOK(map[string]interface{}{"Data": data}, key)
+	matches := q.Find(map[string]interface{}{"Data": data})
+	if !q.Definite() {
+		return r.assertDataMatches(line, key, matches, expected)
+	}
+	var actual interface{}
+	ok := len(matches) > 0
+	if ok {
+		actual = matches[0]
+	}
 	if !ok && expected.Data != nil {
-		r.log(key, fmt.Sprintf("expected %s: %s  actual: (missing)", expected.Type(), expected))
+		r.logMismatch(key, expected.Type(), expected.String(), "", "(missing)")
 		return false
 	}
 	// capture any vars (// e.g. {placeholder})
@@ -366,15 +737,63 @@ func (r *Runner) assertData(line *parse.Line, data interface{}, errData error, k
 			actualString = fmt.Sprintf(`"%s"`, v)
 		}
 		if expected.Type() == actualVal.Type() {
-			r.log(key, fmt.Sprintf("expected: %s  actual: %s", expected, actualString))
+			r.logMismatch(key, "", expected.String(), "", actualString)
 		} else {
-			r.log(key, fmt.Sprintf("expected %s: %s  actual %T: %s", expected.Type(), expected, actual, actualString))
+			r.logMismatch(key, expected.Type(), expected.String(), fmt.Sprintf("%T", actual), actualString)
 		}
 		return false
 	}
 	return true
 }
 
+func (r *Runner) assertMarkup(line *parse.Line, key string, actual interface{}, found bool, err error, expected *parse.Value) bool {
+	if err != nil {
+		r.logMismatch(key, expected.Type(), expected.String(), "", err.Error())
+		return false
+	}
+	if !found {
+		if expected.Data == nil {
+			return true
+		}
+		r.logMismatch(key, expected.Type(), expected.String(), "", "(missing)")
+		return false
+	}
+	return r.assertDetail(line, key, actual, expected)
+}
+
+// assertDataMatches asserts the values found by a query that can
+// match many values, like Data.items[*].id.
+// Lists are compared with all of the matches, other values pass
+// if any match is equal.
+func (r *Runner) assertDataMatches(line *parse.Line, key string, matches []interface{}, expected *parse.Value) bool {
+	if matches == nil {
+		matches = []interface{}{}
+	}
+	if _, ok := expected.Data.([]interface{}); ok {
+		if !expected.Equal(matches) {
+			r.logMismatch(key, "", expected.String(), "", parse.Value{Data: matches}.String())
+			return false
+		}
+		if capture := line.Capture(); len(capture) > 0 {
+			r.capture(capture, matches)
+		}
+		return true
+	}
+	if expected.Data == nil && len(matches) == 0 {
+		return true
+	}
+	for _, match := range matches {
+		if expected.Equal(match) {
+			if capture := line.Capture(); len(capture) > 0 {
+				r.capture(capture, match)
+			}
+			return true
+		}
+	}
+	r.logMismatch(key, "any", expected.String(), "", parse.Value{Data: matches}.String())
+	return false
+}
+
 // assertJSONIsEqualOrSubset returns true if v1 and v2 are equal in value
 // or if both are maps (of type map[string]interface{}) and v1 is a subset of v2, where
 // all keys that are present in v1 are present with the same value in v2.
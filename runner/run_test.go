@@ -1,14 +1,22 @@
 package runner_test
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
 	"net/http/httptest"
+	"net/url"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/cheekybits/is"
+	"github.com/matryer/silk/coverage"
+	"github.com/matryer/silk/openapi"
 	"github.com/matryer/silk/parse"
 	"github.com/matryer/silk/runner"
 	"github.com/matryer/silk/testutil"
@@ -197,6 +205,737 @@ func TestCookies(t *testing.T) {
 	is.False(subT.Failed())
 }
 
+func TestCookieJar(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	s := httptest.NewServer(testutil.SessionHandler())
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	r.RunFile("../testfiles/success/cookie-jar.silk.md")
+	is.False(subT.Failed())
+}
+
+func TestRunnerJar(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	s := httptest.NewServer(testutil.SessionHandler())
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	jar, err := cookiejar.New(nil)
+	is.NoErr(err)
+	r.Jar = jar
+	g, err := parse.Parse("runner-jar.silk.md", strings.NewReader(`# Login
+## POST /login
+===
+* Status: 200
+# Another group
+## GET /me
+===
+* Status: 200`))
+	is.NoErr(err)
+	r.RunGroup(g...)
+	is.False(subT.Failed())
+}
+
+func TestRunnerJarClear(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	s := httptest.NewServer(testutil.SessionHandler())
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	jar, err := cookiejar.New(nil)
+	is.NoErr(err)
+	u, err := url.Parse(s.URL)
+	is.NoErr(err)
+	jar.SetCookies(u, []*http.Cookie{{Name: "session", Value: "seeded", Path: "/"}})
+	r.Jar = jar
+	g, err := parse.Parse("runner-jar-clear.silk.md", strings.NewReader(`# Session
+## GET /me
+===
+* Status: 200
+## GET /me
+* CookieJar: "clear"
+===
+* Status: 401
+# Another group
+## GET /me
+===
+* Status: 401`))
+	is.NoErr(err)
+	r.RunGroup(g...)
+	is.False(subT.Failed())
+	// the caller's jar is left alone
+	is.Equal(r.Jar, jar)
+	is.Equal(len(jar.Cookies(u)), 1)
+}
+
+func TestMultiValuedHeaders(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	s := httptest.NewServer(testutil.HeadersHandler())
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	r.RunFile("../testfiles/success/multi-headers.silk.md")
+	is.False(subT.Failed())
+}
+
+func TestFailureMissingCount(t *testing.T) {
+	is := is.New(t)
+	s := httptest.NewServer(testutil.HeadersHandler())
+	defer s.Close()
+	// only missing headers have a count of zero
+	for _, test := range []struct {
+		key  string
+		fail bool
+	}{
+		{key: "Warning.Count"},
+		{key: "X-Missing.Count"},
+		{key: "Status-URI.Count"},
+		{key: "Cookie-Policy.Count"},
+		{key: "Data-Version.Count"},
+		{key: "URL-Hash.Count"},
+		{key: "Body-Digest.Count"},
+		{key: "Status.Count", fail: true},
+		{key: "Redirects.Count", fail: true},
+		{key: "Data.Count", fail: true},
+		{key: "Cookies.Count", fail: true},
+		{key: "Body.items.Count", fail: true},
+		{key: "Vary[0].Count", fail: true},
+	} {
+		subT := &testT{}
+		r := runner.New(subT, s.URL)
+		r.Log = func(string) {}
+		g, err := parse.Parse("count.silk.md", strings.NewReader(`# Count
+## GET /headers
+===
+* `+test.key+`: 0`))
+		is.NoErr(err)
+		r.RunGroup(g...)
+		is.Equal(subT.Failed(), test.fail)
+	}
+}
+
+func TestFailureMultiValuedHeaders(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	s := httptest.NewServer(testutil.HeadersHandler())
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	r.RunFile("../testfiles/failure/headers.failure.any.silk.md")
+	is.True(subT.Failed())
+	logstr := strings.Join(logs, "\n")
+	is.True(strings.Contains(logstr, `Vary[*] expected any: "Cookie"  actual: ["Accept" "Origin"]`))
+}
+
+func TestRedirects(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	s := httptest.NewServer(testutil.RedirectHandler())
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	r.RunFile("../testfiles/success/redirects.silk.md")
+	is.False(subT.Failed())
+}
+
+func TestFollowRedirects(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	s := httptest.NewServer(testutil.RedirectHandler())
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	r.FollowRedirects = true
+	g, err := parse.Parse("follow-redirects.silk.md", strings.NewReader(`# Redirects
+## GET /redirect/3
+===
+* Status: 200
+* Redirects: 3
+## GET /redirect/1
+* FollowRedirects: false
+===
+* Status: 302`))
+	is.NoErr(err)
+	r.RunGroup(g...)
+	is.False(subT.Failed())
+}
+
+func TestRedirectOtherHost(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	var header http.Header
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+	}))
+	defer other.Close()
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, other.URL+"/landed", http.StatusFound)
+	}))
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	r.FollowRedirects = true
+	g, err := parse.Parse("redirect-other-host.silk.md", strings.NewReader(`# Redirects
+## GET /away
+* Authorization: Bearer secret
+* Cookie: session=secret
+* X-Request-Id: 1
+===
+* Status: 200
+* Redirects: 1`))
+	is.NoErr(err)
+	r.RunGroup(g...)
+	is.False(subT.Failed())
+	is.NotNil(header)
+	is.Equal(header.Get("Authorization"), "")
+	is.Equal(header.Get("Cookie"), "")
+	is.Equal(header.Get("X-Request-Id"), "1")
+}
+
+func TestRedirectCookieWithJar(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	var cookies []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/away" {
+			http.SetCookie(w, &http.Cookie{Name: "theme", Value: "dark", Path: "/"})
+			http.Redirect(w, r, "/landed", http.StatusFound)
+			return
+		}
+		cookies = r.Header["Cookie"]
+	}))
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	r.FollowRedirects = true
+	jar, err := cookiejar.New(nil)
+	is.NoErr(err)
+	r.Jar = jar
+	g, err := parse.Parse("redirect-cookie-jar.silk.md", strings.NewReader(`# Redirects
+## GET /away
+* Cookie: session=secret
+===
+* Status: 200
+* Redirects: 1`))
+	is.NoErr(err)
+	r.RunGroup(g...)
+	is.False(subT.Failed())
+	// the explicit cookie is kept on the same host, and the
+	// jar's cookies are added once
+	is.Equal(cookies, []string{"session=secret; theme=dark"})
+}
+
+func TestMultipart(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	s := httptest.NewServer(testutil.EchoMultipartHandler())
+	defer s.Close()
+	os.Setenv("$AppNameFromEnv", "Silk")
+	r := runner.New(subT, s.URL)
+	r.RunFile("../testfiles/success/multipart.silk.md")
+	is.False(subT.Failed())
+}
+
+func TestFailureMultipartMissingFile(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	s := httptest.NewServer(testutil.EchoMultipartHandler())
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	g, err := parse.Parse("missing-file.silk.md", strings.NewReader(`# Uploads
+## POST /upload
+* +name=Silk
+* +document=@no-such-file.txt
+===
+* Status: 200`))
+	is.NoErr(err)
+	r.RunGroup(g...)
+	is.True(subT.Failed())
+	logstr := strings.Join(logs, "\n")
+	// the failure is at the part's line
+	is.True(strings.Contains(logstr, "missing-file.silk.md:4"))
+	is.True(strings.Contains(logstr, "invalid form part:"))
+	is.True(strings.Contains(logstr, "no-such-file.txt"))
+}
+
+func TestFailureMultipartBodyFile(t *testing.T) {
+	is := is.New(t)
+	g, err := parse.Parse("../testfiles/success/parts.silk.md", strings.NewReader(`# Uploads
+## POST /upload
+* +name=Silk
+* Body: @fixtures/create-user.json
+===
+* Status: 200`))
+	is.NoErr(err)
+	r := runner.New(t, "http://localhost:8080")
+	_, err = r.Curl(g[0], g[0].Requests[0])
+	is.Err(err)
+	is.Equal(err.Error(), "../testfiles/success/parts.silk.md:4: - cannot have a body and form parts")
+}
+
+func TestForm(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	s := httptest.NewServer(testutil.EchoDataHandler())
+	defer s.Close()
+	os.Setenv("$AppNameFromEnv", "Silk")
+	r := runner.New(subT, s.URL)
+	r.RunFile("../testfiles/success/form.silk.md")
+	is.False(subT.Failed())
+}
+
+func TestBodyFiles(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	s := httptest.NewServer(testutil.EchoRawHandler())
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	r.RunFile("../testfiles/success/body-files.silk.md")
+	is.False(subT.Failed())
+}
+
+func TestFailureBodyFileMismatch(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	s := httptest.NewServer(testutil.EchoRawHandler())
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	g, err := parse.Parse("../testfiles/success/inline.silk.md", strings.NewReader(`# Body files
+## POST /echo
+* Body: @fixtures/user.json
+===
+* Body: @fixtures/create-user.json`))
+	is.NoErr(err)
+	r.RunGroup(g...)
+	is.True(subT.Failed())
+	logstr := strings.Join(logs, "\n")
+	is.True(strings.Contains(logstr, "../testfiles/success/inline.silk.md:5 - body doesn't match"))
+}
+
+func TestBinaryBody(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	s := httptest.NewServer(testutil.EchoRawHandler())
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	r.RunFile("../testfiles/success/binary.silk.md")
+	is.False(subT.Failed())
+}
+
+func TestFailureBodySize(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	s := httptest.NewServer(testutil.EchoRawHandler())
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	g, err := parse.Parse("../testfiles/success/inline.silk.md", strings.NewReader(`# Binary
+## POST /download
+* Body: @fixtures/binary.bin
+===
+* Body.Size: 10240`))
+	is.NoErr(err)
+	r.RunGroup(g...)
+	is.True(subT.Failed())
+	logstr := strings.Join(logs, "\n")
+	is.True(strings.Contains(logstr, "Body.Size expected: 10240  actual: 16"))
+}
+
+func TestBodyFormats(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	s := httptest.NewServer(testutil.FormatsHandler())
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	r.RunFile("../testfiles/success/formats.silk.md")
+	is.False(subT.Failed())
+}
+
+func TestBodyParsers(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	s := httptest.NewServer(testutil.FormatsHandler())
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	r.BodyParsers["application/x-ndjson"] = func(r io.Reader) (interface{}, error) {
+		return map[string]interface{}{"custom": true}, nil
+	}
+	g, err := parse.Parse("body-parsers.silk.md", strings.NewReader(`# Custom
+## GET /ndjson
+===
+* Data.custom: true`))
+	is.NoErr(err)
+	r.RunGroup(g...)
+	is.False(subT.Failed())
+}
+
+func TestParseBodyWithoutBodyParsers(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	s := httptest.NewServer(testutil.FormatsHandler())
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	r.BodyParsers = nil
+	r.ParseBody = func(r io.Reader) (interface{}, error) {
+		return map[string]interface{}{"custom": true}, nil
+	}
+	g, err := parse.Parse("parse-body.silk.md", strings.NewReader(`# Custom
+## GET /ndjson
+===
+* Data.custom: true`))
+	is.NoErr(err)
+	r.RunGroup(g...)
+	is.False(subT.Failed())
+}
+
+func TestDataQueries(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	s := httptest.NewServer(testutil.EchoDataHandler())
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	r.RunFile("../testfiles/success/data-queries.silk.md")
+	is.False(subT.Failed())
+}
+
+func TestFailureMalformedDataQuery(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	s := httptest.NewServer(testutil.EchoDataHandler())
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	r.RunFile("../testfiles/failure/data.failure.query.silk.md")
+	is.True(subT.Failed())
+	logstr := strings.Join(logs, "\n")
+	is.True(strings.Contains(logstr, `malformed path "Data.body.items[?(@.id==3].id": expected ")]"`))
+}
+
+func TestMarkup(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	s := httptest.NewServer(testutil.PagesHandler())
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	r.RunFile("../testfiles/success/markup.silk.md")
+	is.False(subT.Failed())
+}
+
+func TestFailureMarkup(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	s := httptest.NewServer(testutil.PagesHandler())
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	r.RunFile("../testfiles/failure/markup.failure.silk.md")
+	is.True(subT.Failed())
+	logstr := strings.Join(logs, "\n")
+	is.True(strings.Contains(logstr, `CSS(h1.title) expected: "Goodbye"  actual: "Welcome"`))
+}
+
+func TestSchema(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	s := httptest.NewServer(testutil.EchoRawHandler())
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	r.RunFile("../testfiles/success/schema.silk.md")
+	is.False(subT.Failed())
+}
+
+func TestFailureSchema(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	s := httptest.NewServer(testutil.EchoRawHandler())
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	r.RunFile("../testfiles/failure/schema.failure.silk.md")
+	is.True(subT.Failed())
+	logstr := strings.Join(logs, "\n")
+	is.True(strings.Contains(logstr, `Schema /address: missing required property "city"`))
+	is.True(strings.Contains(logstr, "Schema /id: expected integer but got string"))
+}
+
+func TestOpenAPI(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	s := httptest.NewServer(testutil.UsersHandler())
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	spec, err := openapi.Load("../testfiles/success/openapi.yaml")
+	is.NoErr(err)
+	r.OpenAPI = spec
+	r.RunFile("../testfiles/success/users.silk.md")
+	is.False(subT.Failed())
+}
+
+func TestFailureOpenAPI(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	s := httptest.NewServer(testutil.UsersHandler())
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	spec, err := openapi.Load("../testfiles/success/openapi.yaml")
+	is.NoErr(err)
+	r.OpenAPI = spec
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	r.RunFile("../testfiles/failure/openapi.failure.silk.md")
+	is.True(subT.Failed())
+	logstr := strings.Join(logs, "\n")
+	is.True(strings.Contains(logstr, `OpenAPI path parameter "id": expected integer but got string`))
+	is.True(strings.Contains(logstr, `OpenAPI query parameter "verbose": not documented`))
+	is.True(strings.Contains(logstr, "openapi.failure.silk.md:3"))
+}
+
+func TestFailureOpenAPIAssertionsFirst(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	s := httptest.NewServer(testutil.UsersHandler())
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	spec, err := openapi.Load("../testfiles/success/openapi.yaml")
+	is.NoErr(err)
+	r.OpenAPI = spec
+	r.Log = func(string) {}
+	var results []*runner.Result
+	r.Record = func(res *runner.Result) {
+		results = append(results, res)
+	}
+	g, err := parse.Parse("openapi.silk.md", strings.NewReader(`# Users
+## GET /users/abc
+* ?verbose=true
+===
+* Status: 200`))
+	is.NoErr(err)
+	r.RunGroup(g...)
+	is.True(subT.Failed())
+	is.Equal(len(results), 1)
+	// the document's own failure is reported, not the contract's
+	is.Equal(results[0].Failure.Line, 5)
+	is.Equal(results[0].Failure.Key, "Status")
+}
+
+func TestCoverage(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	s := httptest.NewServer(testutil.UsersHandler())
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	spec, err := coverage.Load("../testfiles/success/routes.txt")
+	is.NoErr(err)
+	r.Coverage = coverage.New(spec)
+	r.RunFile("../testfiles/success/users.silk.md")
+	is.False(subT.Failed())
+	summary := r.Coverage.Summary()
+	is.Equal(summary.Covered, 4)
+	is.Equal(summary.Total, 5)
+	is.Equal(len(summary.Unmatched), 0)
+	get := summary.Operations[2]
+	is.Equal(get.Path, "/users/{id}")
+	is.Equal(get.Hits, 2)
+	is.Equal(len(get.Statuses), 2)
+}
+
+func TestCoverageBasePath(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	s := httptest.NewServer(http.StripPrefix("/api", testutil.UsersHandler()))
+	defer s.Close()
+	r := runner.New(subT, s.URL+"/api")
+	spec, err := coverage.Load("../testfiles/success/routes.txt")
+	is.NoErr(err)
+	r.Coverage = coverage.New(spec)
+	r.RunFile("../testfiles/success/users.silk.md")
+	is.False(subT.Failed())
+	summary := r.Coverage.Summary()
+	// the routes don't include /api
+	is.Equal(summary.Covered, 4)
+	is.Equal(len(summary.Unmatched), 0)
+}
+
+func TestGroupVars(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	s := httptest.NewServer(testutil.EchoHandler())
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	r.RunFile("../testfiles/success/vars.silk.md")
+	is.False(subT.Failed())
+}
+
+func TestLoadVars(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	s := httptest.NewServer(testutil.EchoHandler())
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	is.NoErr(r.LoadVars("../testfiles/success/vars.json"))
+	// loaded variables override the group's
+	g, err := parse.Parse("load-vars.silk.md", strings.NewReader(`# Variables
+* {name}: "Silk"
+* {greeting}: "Hello {name}"
+* {limit}: 2
+## GET /echo
+* ?greeting={greeting}
+* ?limit={limit}
+===
+* Status: 200
+* Body: /greeting=Hello Environment/
+* Body: /limit=5/`))
+	is.NoErr(err)
+	r.RunGroup(g...)
+	is.False(subT.Failed())
+	is.Err(r.LoadVars("../testfiles/success/vars.silk.md"))
+}
+
+func TestFailureLogCurl(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	s := httptest.NewServer(testutil.UsersHandler())
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	r.LogCurl = true
+	var logs []string
+	r.Log = func(s string) {
+		logs = append(logs, s)
+	}
+	r.RunFile("../testfiles/failure/curl.failure.silk.md")
+	is.True(subT.Failed())
+	logstr := strings.Join(logs, "\n")
+	is.True(strings.Contains(logstr, "curl -X POST '"+s.URL+"/users?notify=true' \\\n"))
+	is.True(strings.Contains(logstr, "-H 'Content-Type: application/json' \\\n"))
+	is.True(strings.Contains(logstr, `--data-binary '{"name": "Pat O'\''Brien"}'`))
+
+	// requests that can't be made are logged too
+	s.Close()
+	logs = nil
+	r.RunFile("../testfiles/failure/curl.failure.silk.md")
+	logstr = strings.Join(logs, "\n")
+	is.True(strings.Contains(logstr, "curl -X POST '"+s.URL+"/users?notify=true' \\\n"))
+}
+
+func TestCurl(t *testing.T) {
+	is := is.New(t)
+	groups, err := parse.ParseFile("../testfiles/failure/curl.failure.silk.md")
+	is.NoErr(err)
+	r := runner.New(t, "http://localhost:8080")
+	cmd, err := r.Curl(groups[0], groups[0].Requests[0])
+	is.NoErr(err)
+	is.Equal(cmd, `curl -X POST 'http://localhost:8080/users?notify=true' \
+  -H 'Content-Type: application/json' \
+  --data-binary '{"name": "Pat O'\''Brien"}'`)
+
+	req, err := http.NewRequest("HEAD", "http://localhost:8080/a b", nil)
+	is.NoErr(err)
+	req.Header.Set("X-Empty", "")
+	is.Equal(runner.CurlCommand(req, "", true), `curl --head -L http://localhost:8080/a%20b \
+  -H 'X-Empty;'`)
+}
+
+func TestCurlBodies(t *testing.T) {
+	is := is.New(t)
+	os.Setenv("$AppNameFromEnv", "Silk")
+	r := runner.New(t, "http://localhost:8080")
+	// body files are sent from the file
+	groups, err := parse.ParseFile("../testfiles/success/binary.silk.md")
+	is.NoErr(err)
+	binary, err := r.Curl(groups[0], groups[0].Requests[0])
+	is.NoErr(err)
+	is.Equal(binary, `curl -X POST http://localhost:8080/download \
+  --data-binary @../testfiles/success/fixtures/binary.bin`)
+	// form parts are sent with -F
+	groups, err = parse.ParseFile("../testfiles/success/multipart.silk.md")
+	is.NoErr(err)
+	multipart, err := r.Curl(groups[0], groups[0].Requests[0])
+	is.NoErr(err)
+	is.Equal(multipart, `curl -X POST http://localhost:8080/upload \
+  --form-string name=Silk \
+  --form-string tags=testing \
+  --form-string tags=markdown \
+  -F 'document=@../testfiles/success/fixtures/hello.txt;type=text/plain' \
+  -F 'attachment=@../testfiles/success/fixtures/hello.txt;type=application/octet-stream'`)
+	// other bodies are only written if they are text
+	req, err := http.NewRequest("POST", "http://localhost:8080/download", nil)
+	is.NoErr(err)
+	is.Equal(runner.CurlCommand(req, "\x89PNG\x00", false), `# the 5 byte body isn't text, so it is left out
+curl -X POST http://localhost:8080/download`)
+
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		return
+	}
+	for _, cmd := range []string{binary, multipart} {
+		check := exec.Command(sh, "-n")
+		check.Stdin = strings.NewReader(cmd)
+		is.NoErr(check.Run())
+	}
+}
+
+func TestRecord(t *testing.T) {
+	is := is.New(t)
+	subT := &testT{}
+	s := httptest.NewServer(testutil.EchoHandler())
+	defer s.Close()
+	r := runner.New(subT, s.URL)
+	r.Log = func(s string) {}
+	var results []*runner.Result
+	r.Record = func(res *runner.Result) {
+		results = append(results, res)
+	}
+	r.RunFile("../testfiles/success/echo.success.silk.md", "../testfiles/failure/echo.failure.wrongheader.silk.md")
+	is.True(subT.Failed())
+	is.True(len(results) > 1)
+	is.True(results[0].Passed)
+	is.Nil(results[0].Failure)
+	is.Equal(results[0].Method, "GET")
+	is.NotNil(results[0].Response)
+	is.Equal(results[0].Response.Status, http.StatusOK)
+	failed := results[len(results)-1]
+	is.False(failed.Passed)
+	is.Equal(failed.Filename, "../testfiles/failure/echo.failure.wrongheader.silk.md")
+	is.Equal(failed.Group, "Echo server")
+	is.Equal(failed.Line, 3)
+	is.Equal(failed.URL, s.URL+"/echo")
+	is.Equal(failed.Failure.Line, 22)
+	is.Equal(failed.Failure.Message, "Content-Type doesn't match")
+	is.Equal(failed.Failure.Key, "Content-Type")
+	is.Equal(failed.Failure.Expected, `"wrong/type"`)
+	is.Equal(failed.Failure.Actual, `"text/plain; charset=utf-8"`)
+	is.True(len(failed.Failure.Log) > 0)
+
+	var buf bytes.Buffer
+	is.NoErr(runner.WriteResults(&buf, results))
+	decoded, err := runner.ReadResults(&buf)
+	is.NoErr(err)
+	is.Equal(len(decoded), len(results))
+	is.Equal(decoded[len(decoded)-1].Failure.Key, "Content-Type")
+}
+
 func TestFailureFieldsSameType(t *testing.T) {
 	is := is.New(t)
 	subT := &testT{}
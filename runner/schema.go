@@ -0,0 +1,51 @@
+package runner
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/matryer/silk/schema"
+)
+
+// schemaKey is the expected detail that validates the response
+// data against a JSON Schema file, like "schemas/user.json" or
+// "schemas/api.json#/definitions/user".
+const schemaKey = "Schema"
+
+// schemaBodyType is the type of expected code blocks that contain
+// (or reference) a JSON Schema instead of the expected body.
+const schemaBodyType = "jsonschema"
+
+// loadSchema loads the schema at ref, which is relative to dir
+// and may end with a #fragment.
+func loadSchema(dir, ref string) (*schema.Schema, error) {
+	file, fragment := ref, ""
+	if i := strings.Index(ref, "#"); i > -1 {
+		file, fragment = ref[:i], ref[i:]
+	}
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(dir, file)
+	}
+	s, err := schema.Load(file)
+	if err != nil {
+		return nil, err
+	}
+	if len(fragment) > 1 {
+		return s.Ref(fragment)
+	}
+	return s, nil
+}
+
+// assertSchema validates the data against the schema, logging
+// every violation.
+func (r *Runner) assertSchema(s *schema.Schema, data interface{}, errData error) bool {
+	if errData != nil {
+		r.log(schemaKey, "failed to parse body:", errData)
+		return false
+	}
+	violations := s.Validate(data)
+	for _, v := range violations {
+		r.log(schemaKey, v)
+	}
+	return len(violations) == 0
+}
@@ -0,0 +1,56 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+
+	"github.com/matryer/silk/parse"
+)
+
+// varKeyRegexp matches the keys of group details that set
+// variables, like {host}.
+var varKeyRegexp = regexp.MustCompile(`^{([^{}]+)}$`)
+
+// LoadVars loads variables from a JSON file of names and values:
+//
+//	{"host": "localhost:8080", "token": "abc123"}
+//
+// Loaded variables override those declared in groups, so a file
+// can describe an environment.
+func (r *Runner) LoadVars(filename string) error {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	var vars map[string]interface{}
+	if err := json.Unmarshal(b, &vars); err != nil {
+		return fmt.Errorf("%s: %s", filename, err)
+	}
+	for k, v := range vars {
+		r.vars[k] = &parse.Value{Data: v}
+		r.fileVars[k] = true
+	}
+	return nil
+}
+
+// setGroupVars sets the variables declared in the
+// details of the group.
+func (r *Runner) setGroupVars(group *parse.Group) {
+	for _, line := range group.Details {
+		detail := line.Detail()
+		if detail == nil {
+			continue
+		}
+		matches := varKeyRegexp.FindStringSubmatch(detail.Key)
+		if matches == nil || r.fileVars[matches[1]] {
+			continue
+		}
+		val := detail.Value.Data
+		if s, ok := val.(string); ok {
+			val = r.resolveVars(s)
+		}
+		r.capture(matches[1], val)
+	}
+}
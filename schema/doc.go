@@ -0,0 +1,6 @@
+// Package schema validates decoded data against JSON Schemas.
+//
+// The common keywords of JSON Schema (drafts 4 to 7) are
+// supported, along with $ref to local files and the OpenAPI
+// nullable keyword.
+package schema
@@ -0,0 +1,260 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Violation describes a way in which data does not
+// match a schema.
+type Violation struct {
+	// Pointer is the JSON pointer to the invalid value,
+	// or an empty string for the root.
+	Pointer string
+	// Message describes the problem.
+	Message string
+}
+
+func (v Violation) String() string {
+	pointer := v.Pointer
+	if len(pointer) == 0 {
+		pointer = "(root)"
+	}
+	return pointer + ": " + v.Message
+}
+
+// Schema is a JSON Schema.
+type Schema struct {
+	loader *loader
+	path   string
+	node   interface{}
+}
+
+// Load loads the schema in the specified JSON or YAML file.
+func Load(path string) (*Schema, error) {
+	l := newLoader()
+	path = filepath.Clean(path)
+	doc, err := l.load(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Schema{loader: l, path: path, node: doc}, nil
+}
+
+// Parse parses a JSON schema as if it were in the file at path,
+// so references to other files are resolved relative to it.
+func Parse(b []byte, path string) (*Schema, error) {
+	var doc interface{}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+	return New(doc, path), nil
+}
+
+// New makes a Schema from decoded JSON (or YAML) data, found
+// in the file at path.
+// References are resolved relative to path, and references
+// to the same file (like #/definitions/user) use doc.
+func New(doc interface{}, path string) *Schema {
+	l := newLoader()
+	path = filepath.Clean(path)
+	l.docs[path] = doc
+	return &Schema{loader: l, path: path, node: doc}
+}
+
+// Ref gets the schema at the reference (like #/definitions/user
+// or user.json) from this schema's document.
+func (s *Schema) Ref(ref string) (*Schema, error) {
+	node, path, err := s.loader.resolve(ref, s.path)
+	if err != nil {
+		return nil, err
+	}
+	return &Schema{loader: s.loader, path: path, node: node}, nil
+}
+
+// Sub gets the schema for node, a value within this schema's
+// document (like the schema of an OpenAPI operation), so that its
+// references are resolved against the document.
+func (s *Schema) Sub(node interface{}) *Schema {
+	return &Schema{loader: s.loader, path: s.path, node: node}
+}
+
+// Validate validates the data against the schema, and returns
+// all of the violations ordered by pointer.
+// Data must be in the form produced by encoding/json.
+func (s *Schema) Validate(data interface{}) []Violation {
+	v := &validator{loader: s.loader}
+	v.validate(s.node, s.path, data, "")
+	sort.SliceStable(v.violations, func(i, j int) bool {
+		return v.violations[i].Pointer < v.violations[j].Pointer
+	})
+	return v.violations
+}
+
+// loader loads and caches schema documents.
+type loader struct {
+	docs map[string]interface{}
+}
+
+func newLoader() *loader {
+	return &loader{docs: make(map[string]interface{})}
+}
+
+func (l *loader) load(path string) (interface{}, error) {
+	if doc, ok := l.docs[path]; ok {
+		return doc, nil
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := Decode(b, path)
+	if err != nil {
+		return nil, err
+	}
+	l.docs[path] = doc
+	return doc, nil
+}
+
+// Decode decodes a JSON or YAML (if path ends with .yaml or .yml)
+// document into the form produced by encoding/json.
+func Decode(b []byte, path string) (interface{}, error) {
+	var doc interface{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(b, &doc); err != nil {
+			return nil, fmt.Errorf("%s: %s", path, err)
+		}
+		var err error
+		if doc, err = Normalize(doc); err != nil {
+			return nil, fmt.Errorf("%s: %s", path, err)
+		}
+	default:
+		if err := json.Unmarshal(b, &doc); err != nil {
+			return nil, fmt.Errorf("%s: %s", path, err)
+		}
+	}
+	return doc, nil
+}
+
+// resolve gets the node referenced by ref from the document
+// at path, and the path of the document containing the node.
+func (l *loader) resolve(ref, path string) (interface{}, string, error) {
+	file, fragment := ref, ""
+	if i := strings.Index(ref, "#"); i > -1 {
+		file, fragment = ref[:i], ref[i+1:]
+	}
+	if len(file) > 0 {
+		if filepath.IsAbs(file) {
+			path = filepath.Clean(file)
+		} else {
+			path = filepath.Join(filepath.Dir(path), file)
+		}
+	}
+	doc, err := l.load(path)
+	if err != nil {
+		return nil, path, err
+	}
+	node, err := Pointer(doc, fragment)
+	if err != nil {
+		return nil, path, fmt.Errorf("%s: %s", ref, err)
+	}
+	return node, path, nil
+}
+
+// Pointer gets the value in doc at the JSON pointer.
+func Pointer(doc interface{}, pointer string) (interface{}, error) {
+	if len(pointer) == 0 {
+		return doc, nil
+	}
+	if unescaped, err := url.PathUnescape(pointer); err == nil {
+		pointer = unescaped
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid pointer %q", pointer)
+	}
+	node := doc
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+		switch n := node.(type) {
+		case map[string]interface{}:
+			var ok bool
+			if node, ok = n[token]; !ok {
+				return nil, fmt.Errorf("missing %q", token)
+			}
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(n) {
+				return nil, fmt.Errorf("missing %q", token)
+			}
+			node = n[i]
+		default:
+			return nil, fmt.Errorf("missing %q", token)
+		}
+	}
+	return node, nil
+}
+
+// escape escapes a key for use in a JSON pointer.
+func escape(key string) string {
+	return strings.Replace(strings.Replace(key, "~", "~0", -1), "/", "~1", -1)
+}
+
+// Normalize turns decoded data, like YAML or MessagePack, into the
+// shape produced by encoding/json: maps have string keys, and
+// numbers are float64.
+func Normalize(v interface{}) (interface{}, error) {
+	switch val := v.(type) {
+	case nil, string, bool, float64:
+		return val, nil
+	case []byte:
+		return string(val), nil
+	case map[string]interface{}:
+		for k, item := range val {
+			n, err := Normalize(item)
+			if err != nil {
+				return nil, err
+			}
+			val[k] = n
+		}
+		return val, nil
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			n, err := Normalize(item)
+			if err != nil {
+				return nil, err
+			}
+			m[fmt.Sprint(k)] = n
+		}
+		return m, nil
+	case []interface{}:
+		for i, item := range val {
+			n, err := Normalize(item)
+			if err != nil {
+				return nil, err
+			}
+			val[i] = n
+		}
+		return val, nil
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), nil
+	}
+	return nil, fmt.Errorf("unsupported value %v (%T)", v, v)
+}
@@ -0,0 +1,115 @@
+package schema_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/cheekybits/is"
+	"github.com/matryer/silk/schema"
+)
+
+func decode(t *testing.T, s string) interface{} {
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatalf("bad JSON: %s", err)
+	}
+	return v
+}
+
+func TestValidateValid(t *testing.T) {
+	is := is.New(t)
+	s, err := schema.Load("../testfiles/success/schemas/user.json")
+	is.NoErr(err)
+	violations := s.Validate(decode(t, `{
+		"id": 1,
+		"name": "Silk",
+		"email": "silk@example.com",
+		"role": "admin",
+		"tags": ["testing", "markdown"],
+		"address": {"city": "London", "postcode": null}
+	}`))
+	is.Equal(len(violations), 0)
+}
+
+func TestValidateViolations(t *testing.T) {
+	is := is.New(t)
+	s, err := schema.Load("../testfiles/success/schemas/user.json")
+	is.NoErr(err)
+	violations := s.Validate(decode(t, `{
+		"id": 1.5,
+		"name": "",
+		"email": "not an email",
+		"role": "owner",
+		"tags": ["ok", "NOT", "ok"],
+		"address": {"postcode": "TOO LONG POSTCODE"},
+		"extra": true
+	}`))
+	var actual []string
+	for _, v := range violations {
+		actual = append(actual, v.String())
+	}
+	expected := []string{
+		`(root): unexpected property "extra"`,
+		`/address: missing required property "city"`,
+		`/address/postcode: length must be <= 8`,
+		`/email: must be a valid email`,
+		`/id: expected integer but got number`,
+		`/name: length must be >= 1`,
+		`/role: must be one of ["admin","member"]`,
+		`/tags: items must be unique (0 and 2 are equal)`,
+		`/tags/1: must match pattern "^[a-z]+$"`,
+	}
+	is.Equal(len(actual), len(expected))
+	for i := range expected {
+		is.Equal(actual[i], expected[i])
+	}
+}
+
+func TestValidateCombinators(t *testing.T) {
+	is := is.New(t)
+	s, err := schema.Parse([]byte(`{
+		"oneOf": [{"type": "string"}, {"type": "number", "multipleOf": 5}],
+		"not": {"const": 10}
+	}`), "inline.json")
+	is.NoErr(err)
+	is.Equal(len(s.Validate("hello")), 0)
+	is.Equal(len(s.Validate(15.0)), 0)
+	is.Equal(len(s.Validate(7.0)), 1)
+	is.Equal(len(s.Validate(10.0)), 1)
+	is.Equal(len(s.Validate(true)), 1)
+}
+
+func TestValidateMissingRef(t *testing.T) {
+	is := is.New(t)
+	s, err := schema.Parse([]byte(`{"$ref": "nope.json"}`), "inline.json")
+	is.NoErr(err)
+	violations := s.Validate("anything")
+	is.Equal(len(violations), 1)
+}
+
+func TestPointer(t *testing.T) {
+	is := is.New(t)
+	doc := decode(t, `{"a/b": {"c~d": [1, 2]}}`)
+	v, err := schema.Pointer(doc, "/a~1b/c~0d/1")
+	is.NoErr(err)
+	is.Equal(v, 2.0)
+	_, err = schema.Pointer(doc, "/missing")
+	is.Err(err)
+}
+
+func TestNormalize(t *testing.T) {
+	is := is.New(t)
+	v, err := schema.Normalize(map[interface{}]interface{}{
+		"id":   1,
+		2:      uint8(3),
+		"tags": []interface{}{int64(4), "silk", []byte("bytes")},
+	})
+	is.NoErr(err)
+	is.Equal(v, map[string]interface{}{
+		"id":   float64(1),
+		"2":    float64(3),
+		"tags": []interface{}{float64(4), "silk", "bytes"},
+	})
+	_, err = schema.Normalize(struct{}{})
+	is.Err(err)
+}
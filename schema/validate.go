@@ -0,0 +1,365 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxDepth stops references that refer to themselves
+// from recursing forever.
+const maxDepth = 128
+
+type validator struct {
+	loader     *loader
+	violations []Violation
+	depth      int
+}
+
+func (v *validator) addf(pointer, format string, args ...interface{}) {
+	v.violations = append(v.violations, Violation{Pointer: pointer, Message: fmt.Sprintf(format, args...)})
+}
+
+// valid gets whether data matches node, without
+// recording any violations.
+func (v *validator) valid(node interface{}, path string, data interface{}, pointer string) bool {
+	sub := &validator{loader: v.loader, depth: v.depth}
+	sub.validate(node, path, data, pointer)
+	return len(sub.violations) == 0
+}
+
+// validate validates data against the schema node from the
+// document at path.
+func (v *validator) validate(node interface{}, path string, data interface{}, pointer string) {
+	v.depth++
+	defer func() { v.depth-- }()
+	if v.depth > maxDepth {
+		v.addf(pointer, "schema is too deeply nested")
+		return
+	}
+	switch n := node.(type) {
+	case bool:
+		if !n {
+			v.addf(pointer, "no value is allowed")
+		}
+		return
+	case map[string]interface{}:
+		v.validateObject(n, path, data, pointer)
+	}
+}
+
+func (v *validator) validateObject(s map[string]interface{}, path string, data interface{}, pointer string) {
+	if ref, ok := s["$ref"].(string); ok {
+		node, refPath, err := v.loader.resolve(ref, path)
+		if err != nil {
+			v.addf(pointer, "invalid $ref: %s", err)
+			return
+		}
+		v.validate(node, refPath, data, pointer)
+		return
+	}
+	if data == nil && s["nullable"] == true {
+		// OpenAPI nullable
+		return
+	}
+	if t, ok := s["type"]; ok && !matchesType(t, data) {
+		v.addf(pointer, "expected %s but got %s", typeList(t), typeOf(data))
+		return
+	}
+	if enum, ok := s["enum"].([]interface{}); ok {
+		found := false
+		for _, e := range enum {
+			if reflect.DeepEqual(e, data) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.addf(pointer, "must be one of %s", jsonString(enum))
+		}
+	}
+	if c, ok := s["const"]; ok && !reflect.DeepEqual(c, data) {
+		v.addf(pointer, "must be %s", jsonString(c))
+	}
+	switch d := data.(type) {
+	case float64:
+		v.validateNumber(s, d, pointer)
+	case string:
+		v.validateString(s, d, pointer)
+	case []interface{}:
+		v.validateArray(s, path, d, pointer)
+	case map[string]interface{}:
+		v.validateProperties(s, path, d, pointer)
+	}
+	if all, ok := s["allOf"].([]interface{}); ok {
+		for _, sub := range all {
+			v.validate(sub, path, data, pointer)
+		}
+	}
+	if anyOf, ok := s["anyOf"].([]interface{}); ok {
+		matched := false
+		for _, sub := range anyOf {
+			if v.valid(sub, path, data, pointer) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			v.addf(pointer, "must match at least one schema in anyOf")
+		}
+	}
+	if oneOf, ok := s["oneOf"].([]interface{}); ok {
+		matches := 0
+		for _, sub := range oneOf {
+			if v.valid(sub, path, data, pointer) {
+				matches++
+			}
+		}
+		if matches != 1 {
+			v.addf(pointer, "must match exactly one schema in oneOf (matched %d)", matches)
+		}
+	}
+	if not, ok := s["not"]; ok && v.valid(not, path, data, pointer) {
+		v.addf(pointer, "must not match the schema in not")
+	}
+}
+
+func (v *validator) validateNumber(s map[string]interface{}, n float64, pointer string) {
+	if min, ok := s["minimum"].(float64); ok {
+		if s["exclusiveMinimum"] == true && n <= min {
+			v.addf(pointer, "must be > %v", min)
+		} else if n < min {
+			v.addf(pointer, "must be >= %v", min)
+		}
+	}
+	if max, ok := s["maximum"].(float64); ok {
+		if s["exclusiveMaximum"] == true && n >= max {
+			v.addf(pointer, "must be < %v", max)
+		} else if n > max {
+			v.addf(pointer, "must be <= %v", max)
+		}
+	}
+	if min, ok := s["exclusiveMinimum"].(float64); ok && n <= min {
+		v.addf(pointer, "must be > %v", min)
+	}
+	if max, ok := s["exclusiveMaximum"].(float64); ok && n >= max {
+		v.addf(pointer, "must be < %v", max)
+	}
+	if m, ok := s["multipleOf"].(float64); ok && m > 0 {
+		if q := n / m; math.Abs(q-math.Round(q)) > 1e-9 {
+			v.addf(pointer, "must be a multiple of %v", m)
+		}
+	}
+}
+
+func (v *validator) validateString(s map[string]interface{}, str string, pointer string) {
+	length := float64(utf8.RuneCountInString(str))
+	if min, ok := s["minLength"].(float64); ok && length < min {
+		v.addf(pointer, "length must be >= %v", min)
+	}
+	if max, ok := s["maxLength"].(float64); ok && length > max {
+		v.addf(pointer, "length must be <= %v", max)
+	}
+	if pattern, ok := s["pattern"].(string); ok {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			v.addf(pointer, "invalid pattern %q: %s", pattern, err)
+		} else if !re.MatchString(str) {
+			v.addf(pointer, "must match pattern %q", pattern)
+		}
+	}
+	if format, ok := s["format"].(string); ok && !validFormat(format, str) {
+		v.addf(pointer, "must be a valid %s", format)
+	}
+}
+
+func (v *validator) validateArray(s map[string]interface{}, path string, items []interface{}, pointer string) {
+	length := float64(len(items))
+	if min, ok := s["minItems"].(float64); ok && length < min {
+		v.addf(pointer, "must have at least %v items", min)
+	}
+	if max, ok := s["maxItems"].(float64); ok && length > max {
+		v.addf(pointer, "must have at most %v items", max)
+	}
+	if s["uniqueItems"] == true {
+		for i := range items {
+			for j := i + 1; j < len(items); j++ {
+				if reflect.DeepEqual(items[i], items[j]) {
+					v.addf(pointer, "items must be unique (%d and %d are equal)", i, j)
+				}
+			}
+		}
+	}
+	switch schema := s["items"].(type) {
+	case []interface{}:
+		// tuple
+		for i, item := range items {
+			if i < len(schema) {
+				v.validate(schema[i], path, item, pointer+"/"+strconv.Itoa(i))
+			} else if additional, ok := s["additionalItems"]; ok {
+				v.validate(additional, path, item, pointer+"/"+strconv.Itoa(i))
+			}
+		}
+	case nil:
+	default:
+		for i, item := range items {
+			v.validate(schema, path, item, pointer+"/"+strconv.Itoa(i))
+		}
+	}
+}
+
+func (v *validator) validateProperties(s map[string]interface{}, path string, obj map[string]interface{}, pointer string) {
+	if required, ok := s["required"].([]interface{}); ok {
+		for _, r := range required {
+			name, _ := r.(string)
+			if _, ok := obj[name]; !ok {
+				v.addf(pointer, "missing required property %q", name)
+			}
+		}
+	}
+	count := float64(len(obj))
+	if min, ok := s["minProperties"].(float64); ok && count < min {
+		v.addf(pointer, "must have at least %v properties", min)
+	}
+	if max, ok := s["maxProperties"].(float64); ok && count > max {
+		v.addf(pointer, "must have at most %v properties", max)
+	}
+	properties, _ := s["properties"].(map[string]interface{})
+	patterns, _ := s["patternProperties"].(map[string]interface{})
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		itemPointer := pointer + "/" + escape(k)
+		matched := false
+		if schema, ok := properties[k]; ok {
+			matched = true
+			v.validate(schema, path, obj[k], itemPointer)
+		}
+		for pattern, schema := range patterns {
+			if re, err := regexp.Compile(pattern); err == nil && re.MatchString(k) {
+				matched = true
+				v.validate(schema, path, obj[k], itemPointer)
+			}
+		}
+		if matched {
+			continue
+		}
+		switch additional := s["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				v.addf(pointer, "unexpected property %q", k)
+			}
+		case map[string]interface{}:
+			v.validate(additional, path, obj[k], itemPointer)
+		}
+	}
+}
+
+// matchesType gets whether data is of the type (or one
+// of the types) t.
+func matchesType(t interface{}, data interface{}) bool {
+	switch types := t.(type) {
+	case string:
+		return isType(types, data)
+	case []interface{}:
+		for _, t := range types {
+			if s, ok := t.(string); ok && isType(s, data) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func isType(t string, data interface{}) bool {
+	switch t {
+	case "integer":
+		n, ok := data.(float64)
+		return ok && n == math.Trunc(n)
+	case "number":
+		_, ok := data.(float64)
+		return ok
+	}
+	return typeOf(data) == t
+}
+
+func typeOf(data interface{}) string {
+	switch data.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", data)
+}
+
+func typeList(t interface{}) string {
+	if types, ok := t.([]interface{}); ok {
+		var names []string
+		for _, t := range types {
+			names = append(names, fmt.Sprint(t))
+		}
+		return strings.Join(names, " or ")
+	}
+	return fmt.Sprint(t)
+}
+
+func jsonString(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+var uuidRegexp = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// validFormat checks well known formats.
+// Unknown formats are always valid.
+func validFormat(format, s string) bool {
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, s)
+		return err == nil
+	case "date":
+		_, err := time.Parse("2006-01-02", s)
+		return err == nil
+	case "email":
+		addr, err := mail.ParseAddress(s)
+		return err == nil && addr.Address == s
+	case "uri":
+		u, err := url.Parse(s)
+		return err == nil && u.IsAbs()
+	case "uuid":
+		return uuidRegexp.MatchString(s)
+	case "ipv4":
+		ip := net.ParseIP(s)
+		return ip != nil && ip.To4() != nil && strings.Contains(s, ".")
+	case "ipv6":
+		ip := net.ParseIP(s)
+		return ip != nil && strings.Contains(s, ":")
+	}
+	return true
+}
@@ -11,6 +11,9 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/vmihailenco/msgpack"
 )
 
 // EchoHandler gets an http.Handler that echos request data
@@ -162,3 +165,310 @@ func writeSortedQuery(w io.Writer, query url.Values) {
 		}
 	}
 }
+
+// SessionHandler gets an http.Handler that keeps a login
+// session in cookies.
+//
+//	POST /login   sets the session and theme cookies
+//	GET /me       200 if the session cookie is sent, otherwise 401
+//	POST /logout  expires the session cookie
+func SessionHandler() http.Handler {
+	return http.HandlerFunc(handleSession)
+}
+
+func handleSession(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Server", "SessionHandler")
+	switch r.URL.Path {
+	case "/login":
+		expires := time.Date(2031, time.January, 1, 0, 0, 0, 0, time.UTC)
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "silk-session", Path: "/", Expires: expires, MaxAge: 3600, HttpOnly: true})
+		http.SetCookie(w, &http.Cookie{Name: "theme", Value: "dark", Path: "/", Secure: true})
+		fmt.Fprintln(w, "logged in")
+	case "/me":
+		cookie, err := r.Cookie("session")
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprintln(w, "not logged in")
+			return
+		}
+		fmt.Fprintln(w, "session:", cookie.Value)
+	case "/logout":
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "", Path: "/", MaxAge: -1})
+		fmt.Fprintln(w, "logged out")
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// HeadersHandler gets an http.Handler that adds a response
+// header for each query parameter, so
+//
+//	?Vary=Accept&Vary=Origin
+//
+// responds with two Vary headers.
+func HeadersHandler() http.Handler {
+	return http.HandlerFunc(handleHeaders)
+}
+
+func handleHeaders(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Server", "HeadersHandler")
+	for k, vs := range r.URL.Query() {
+		for _, v := range vs {
+			w.Header().Add(k, v)
+		}
+	}
+	fmt.Fprintln(w, strings.ToUpper(r.Method), r.URL.Path)
+}
+
+// RedirectHandler gets an http.Handler that redirects.
+//
+//	/redirect/{n}  302 to /redirect/{n-1}, until /redirect/0 which is 200
+//	/see-other     303 to /redirect/0
+//	/temporary     307 to /echo
+//	/echo          echos the method and body
+func RedirectHandler() http.Handler {
+	return http.HandlerFunc(handleRedirect)
+}
+
+func handleRedirect(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Server", "RedirectHandler")
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/redirect/"):
+		n, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/redirect/"))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		if n > 0 {
+			http.Redirect(w, r, "/redirect/"+strconv.Itoa(n-1), http.StatusFound)
+			return
+		}
+		fmt.Fprintln(w, "done")
+	case r.URL.Path == "/see-other":
+		http.Redirect(w, r, "/redirect/0", http.StatusSeeOther)
+	case r.URL.Path == "/temporary":
+		http.Redirect(w, r, "/echo", http.StatusTemporaryRedirect)
+	case r.URL.Path == "/echo":
+		fmt.Fprintln(w, strings.ToUpper(r.Method), r.URL.Path)
+		if _, err := io.Copy(w, r.Body); err != nil {
+			log.Println("copying request into response failed:", err)
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// EchoMultipartHandler gets an http.Handler that echos
+// multipart/form-data requests in JSON format, with
+// the fields and files that were sent.
+func EchoMultipartHandler() http.Handler {
+	return http.HandlerFunc(handleEchoMultipart)
+}
+
+func handleEchoMultipart(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Server", "EchoMultipartHandler")
+	if err := r.ParseMultipartForm(1 << 20); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	out := make(map[string]interface{})
+	out["method"] = r.Method
+	out["path"] = r.URL.Path
+	out["fields"] = r.MultipartForm.Value
+	files := make(map[string]interface{})
+	for name, headers := range r.MultipartForm.File {
+		for _, header := range headers {
+			f, err := header.Open()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			var content bytes.Buffer
+			_, err = io.Copy(&content, f)
+			f.Close()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			files[name] = map[string]interface{}{
+				"filename":     header.Filename,
+				"content_type": header.Header.Get("Content-Type"),
+				"size":         content.Len(),
+				"content":      content.String(),
+			}
+		}
+	}
+	out["files"] = files
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(out); err != nil {
+		panic(err)
+	}
+}
+
+// FormatsHandler gets an http.Handler that responds with the same
+// order in different formats, depending on the path:
+// /xml, /atom, /yaml, /form, /ndjson and /msgpack.
+func FormatsHandler() http.Handler {
+	return http.HandlerFunc(handleFormats)
+}
+
+func handleFormats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Server", "FormatsHandler")
+	switch r.URL.Path {
+	case "/xml":
+		w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+		fmt.Fprint(w, `<?xml version="1.0"?>
+<order id="123" status="shipped">
+	<customer>Silk</customer>
+	<item sku="A1">Book</item>
+	<item sku="B2">Pen</item>
+	<total>12.50</total>
+</order>`)
+	case "/atom":
+		w.Header().Set("Content-Type", "application/atom+xml")
+		fmt.Fprint(w, `<feed><title>Orders</title><entry><id>123</id></entry></feed>`)
+	case "/yaml":
+		w.Header().Set("Content-Type", "application/x-yaml")
+		fmt.Fprint(w, `order:
+  id: 123
+  customer: Silk
+  shipped: true
+  items:
+    - sku: A1
+      name: Book
+    - sku: B2
+      name: Pen
+  total: 12.5
+`)
+	case "/form":
+		w.Header().Set("Content-Type", "application/x-www-form-urlencoded")
+		fmt.Fprint(w, "id=123&customer=Silk&item=Book&item=Pen")
+	case "/ndjson":
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		fmt.Fprint(w, `{"id":123,"customer":"Silk"}
+{"id":124,"customer":"Mat"}
+`)
+	case "/msgpack":
+		w.Header().Set("Content-Type", "application/msgpack")
+		b, err := msgpack.Marshal(map[string]interface{}{
+			"id":       123,
+			"customer": "Silk",
+			"items":    []string{"Book", "Pen"},
+			"total":    12.5,
+		})
+		if err != nil {
+			panic(err)
+		}
+		w.Write(b)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// PagesHandler gets an http.Handler that responds with
+// an HTML page at /page and an RSS feed at /feed.
+func PagesHandler() http.Handler {
+	return http.HandlerFunc(handlePages)
+}
+
+func handlePages(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Server", "PagesHandler")
+	switch r.URL.Path {
+	case "/page":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, `<!DOCTYPE html>
+<html>
+<head><title>Silk</title></head>
+<body>
+	<h1 class="title">Welcome</h1>
+	<ul id="orders">
+		<li data-id="123"><a href="/orders/123">First order</a></li>
+		<li data-id="124"><a href="/orders/124">Second order</a>
+	</ul>
+	<p>Unclosed paragraph
+</body>
+</html>`)
+	case "/feed":
+		w.Header().Set("Content-Type", "application/rss+xml")
+		fmt.Fprint(w, `<?xml version="1.0"?>
+<rss version="2.0">
+	<channel>
+		<title>Orders</title>
+		<item><guid>123</guid><title>First order</title></item>
+		<item><guid>124</guid><title>Second order</title></item>
+	</channel>
+</rss>`)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// UsersHandler gets an http.Handler for a small JSON API
+// of users, described by testfiles/success/openapi.yaml:
+//
+//	GET /users?limit=1
+//	GET /users/{id}
+//	POST /users
+//	DELETE /users/{id}
+func UsersHandler() http.Handler {
+	return http.HandlerFunc(handleUsers)
+}
+
+var users = []map[string]interface{}{
+	{"id": 1, "name": "Mat", "email": "mat@example.com"},
+	{"id": 2, "name": "David", "email": "david@example.com"},
+}
+
+func handleUsers(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Server", "UsersHandler")
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Request-Id", "abc123")
+	writeJSON := func(status int, v interface{}) {
+		w.WriteHeader(status)
+		if err := json.NewEncoder(w).Encode(v); err != nil {
+			panic(err)
+		}
+	}
+	path := strings.Trim(r.URL.Path, "/")
+	segs := strings.Split(path, "/")
+	if segs[0] != "users" || len(segs) > 2 {
+		writeJSON(http.StatusNotFound, map[string]interface{}{"error": "not found"})
+		return
+	}
+	if len(segs) == 1 {
+		switch r.Method {
+		case "GET":
+			list := users
+			if limit, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && limit < len(list) {
+				list = list[:limit]
+			}
+			writeJSON(http.StatusOK, list)
+		case "POST":
+			var user map[string]interface{}
+			if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
+				writeJSON(http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+				return
+			}
+			user["id"] = len(users) + 1
+			w.Header().Set("Location", fmt.Sprintf("/users/%v", user["id"]))
+			writeJSON(http.StatusCreated, user)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+		return
+	}
+	id, _ := strconv.Atoi(segs[1])
+	if id < 1 || id > len(users) {
+		writeJSON(http.StatusNotFound, map[string]interface{}{"error": "not found"})
+		return
+	}
+	switch r.Method {
+	case "GET":
+		writeJSON(http.StatusOK, users[id-1])
+	case "DELETE":
+		w.Header().Del("Content-Type")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}